@@ -0,0 +1,157 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lookslike
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maxCachedValidators bounds the number of Validators held by CompileCached. Once reached, the Validators
+// cached the longest ago are dropped to make room.
+const maxCachedValidators = 256
+
+type cachedValidator struct {
+	// schema is retained so that the values it contains stay alive for as long as their key is in use.
+	schema    interface{}
+	validator Validator
+}
+
+var compileCache = struct {
+	sync.Mutex
+	validators map[string]cachedValidator
+	// keys holds the keys of validators in the order they were cached, oldest first.
+	keys []string
+}{validators: map[string]cachedValidator{}}
+
+// CompileCached is like Compile, but returns a shared Validator when called again with a structurally
+// identical schema, so that fixed schemas can be compiled once even when they're declared in hot paths, such
+// as per request handlers. It is safe to call from multiple goroutines, and so are the Validators it returns,
+// provided the IsDefs in the schema are; stateful IsDefs such as IsUnique are shared between all users of the
+// cached Validator.
+//
+// Maps, slices, and scalar values are compared by value. Since functions can't be compared, IsDefs are
+// compared by identity: the same IsDef value, such as IsString or one assigned to a package level variable,
+// matches itself and the IsDefs derived from it with Optional and the like, but calling a constructor like
+// IsEqual(1) again produces a new IsDef. Schemas built with fresh IsDefs on every call therefore never hit the
+// cache, so such IsDefs should be declared once and reused. Schemas holding values that can't be compared this
+// way, such as pointers, or IsDefs not created with Is, are compiled as by Compile every time.
+//
+// At most maxCachedValidators Validators are cached, the oldest ones being dropped first, so a steady stream
+// of distinct schemas doesn't grow the cache indefinitely. Schemas that fail to compile are not cached.
+func CompileCached(in interface{}) (Validator, error) {
+	var key strings.Builder
+	if !writeSchemaKey(&key, in) {
+		return Compile(in)
+	}
+
+	compileCache.Lock()
+	defer compileCache.Unlock()
+
+	if cached, ok := compileCache.validators[key.String()]; ok {
+		return cached.validator, nil
+	}
+
+	validator, err := Compile(in)
+	if err != nil {
+		return nil, err
+	}
+	if len(compileCache.keys) >= maxCachedValidators {
+		delete(compileCache.validators, compileCache.keys[0])
+		compileCache.keys = compileCache.keys[1:]
+	}
+	compileCache.validators[key.String()] = cachedValidator{in, validator}
+	compileCache.keys = append(compileCache.keys, key.String())
+	return validator, nil
+}
+
+// writeSchemaKey writes a key to the given builder that is equal for structurally identical schemas. It
+// returns false if the schema holds a value that can't be keyed, in which case it mustn't be cached.
+func writeSchemaKey(b *strings.Builder, in interface{}) bool {
+	switch v := in.(type) {
+	case IsDef:
+		if v.identity == 0 && v.Checker != nil {
+			// Built without Is, so there's no telling its Checker apart from others
+			return false
+		}
+		fmt.Fprintf(b, "IsDef(%q,%d,%t,%t,%t,%q,%t,", v.Name, v.identity, v.Optional, v.CheckKeyMissing, v.hasDefault, v.failureMessage, v.warning)
+		if v.hasDefault && !writeSchemaKey(b, v.defaultValue) {
+			return false
+		}
+		b.WriteString(")")
+		return true
+	case Map:
+		return writeMapSchemaKey(b, "Map", v)
+	case map[string]interface{}:
+		return writeMapSchemaKey(b, "Map", v)
+	case Slice:
+		return writeSliceSchemaKey(b, "Slice", v)
+	case []interface{}:
+		return writeSliceSchemaKey(b, "Slice", v)
+	case LaxSlice:
+		return writeSliceSchemaKey(b, "LaxSlice", v)
+	case nil:
+		b.WriteString("nil")
+		return true
+	}
+
+	switch reflect.TypeOf(in).Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		fmt.Fprintf(b, "%T(%#v)", in, in)
+		return true
+	default:
+		return false
+	}
+}
+
+func writeMapSchemaKey(b *strings.Builder, kind string, m map[string]interface{}) bool {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b.WriteString(kind + "{")
+	for _, k := range keys {
+		fmt.Fprintf(b, "%q:", k)
+		if !writeSchemaKey(b, m[k]) {
+			return false
+		}
+		b.WriteString(",")
+	}
+	b.WriteString("}")
+	return true
+}
+
+func writeSliceSchemaKey(b *strings.Builder, kind string, s []interface{}) bool {
+	b.WriteString(kind + "[")
+	for _, v := range s {
+		if !writeSchemaKey(b, v) {
+			return false
+		}
+		b.WriteString(",")
+	}
+	b.WriteString("]")
+	return true
+}
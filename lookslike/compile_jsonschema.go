@@ -0,0 +1,293 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lookslike
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// jsonSchemaAnnotations are JSON Schema keywords that don't affect validation, and are ignored by
+// CompileJSONSchema.
+var jsonSchemaAnnotations = map[string]bool{
+	"$schema":     true,
+	"$id":         true,
+	"$comment":    true,
+	"title":       true,
+	"description": true,
+	"default":     true,
+	"examples":    true,
+}
+
+// UnsupportedJSONSchemaKeyword is the error type returned by CompileJSONSchema for schemas using keywords
+// it does not support.
+type UnsupportedJSONSchemaKeyword struct {
+	Keyword string
+	// Location is the JSON pointer, such as #/properties/name, of the schema using the keyword.
+	Location string
+}
+
+func (e UnsupportedJSONSchemaKeyword) Error() string {
+	return fmt.Sprintf("Unsupported JSON Schema keyword %#v at %s", e.Keyword, e.Location)
+}
+
+// CompileJSONSchema compiles a Validator from a JSON Schema document, so that existing schemas can be
+// reused for validation in go. Only a subset of JSON Schema is supported, namely the keywords:
+//
+//   - type, either a single type name or a list of them
+//   - properties and required
+//   - items, as a single schema applying to every element
+//   - enum
+//   - minimum and maximum
+//
+// As in JSON Schema, properties and required only apply to objects, items only to arrays, and minimum and
+// maximum only to numbers; use type to require a value to be of a particular type. The annotation keywords
+// $schema, $id, $comment, title, description, default, and examples are ignored. Any other keyword produces
+// an UnsupportedJSONSchemaKeyword error rather than being silently skipped.
+func CompileJSONSchema(schema []byte) (Validator, error) {
+	var node interface{}
+	decoder := json.NewDecoder(bytes.NewReader(schema))
+	// Keep numbers as json.Number so enum values compare equal to numbers of any type
+	decoder.UseNumber()
+	if err := decoder.Decode(&node); err != nil {
+		return nil, fmt.Errorf("could not parse JSON Schema: %v", err)
+	}
+
+	def, err := jsonSchemaIsDef(node, "#")
+	if err != nil {
+		return nil, err
+	}
+	return Compile(def)
+}
+
+// jsonSchemaIsDef translates the schema found at the given location into an IsDef.
+func jsonSchemaIsDef(node interface{}, location string) (IsDef, error) {
+	schema, ok := node.(map[string]interface{})
+	if !ok {
+		return IsDef{}, fmt.Errorf("expected JSON Schema object at %s, got %#v", location, node)
+	}
+
+	// Iterate in a stable order so the first unsupported keyword reported is deterministic
+	keywords := make([]string, 0, len(schema))
+	for k := range schema {
+		keywords = append(keywords, k)
+	}
+	sort.Strings(keywords)
+
+	var defs []IsDef
+	for _, keyword := range keywords {
+		value := schema[keyword]
+		var def IsDef
+		var err error
+		switch keyword {
+		case "type":
+			def, err = jsonSchemaType(value, location)
+		case "properties":
+			def, err = jsonSchemaProperties(value, schema["required"], location)
+		case "required":
+			if _, hasProperties := schema["properties"]; hasProperties {
+				// Handled along with properties
+				continue
+			}
+			def, err = jsonSchemaProperties(map[string]interface{}{}, value, location)
+		case "items":
+			def, err = jsonSchemaItems(value, location)
+		case "enum":
+			allowed, isList := value.([]interface{})
+			if !isList {
+				return IsDef{}, fmt.Errorf("expected list for enum at %s, got %#v", location, value)
+			}
+			def = IsOneOf(allowed...)
+		case "minimum", "maximum":
+			def, err = jsonSchemaBound(keyword, value, location)
+		default:
+			if jsonSchemaAnnotations[keyword] {
+				continue
+			}
+			return IsDef{}, UnsupportedJSONSchemaKeyword{keyword, location}
+		}
+		if err != nil {
+			return IsDef{}, err
+		}
+		defs = append(defs, def)
+	}
+
+	return Is("JSON Schema", func(path Path, v interface{}) *Results {
+		results := ValidResult(path)
+		for _, def := range defs {
+			results.merge(def.Check(path, v, true))
+		}
+		return results
+	}), nil
+}
+
+func jsonSchemaType(value interface{}, location string) (IsDef, error) {
+	var names []interface{}
+	switch typed := value.(type) {
+	case string:
+		names = []interface{}{typed}
+	case []interface{}:
+		names = typed
+	default:
+		return IsDef{}, fmt.Errorf("expected string or list for type at %s, got %#v", location, value)
+	}
+
+	defs := make([]IsDef, len(names))
+	for i, name := range names {
+		switch name {
+		case "string":
+			defs[i] = IsString
+		case "boolean":
+			defs[i] = IsBool
+		case "number":
+			defs[i] = IsNumeric
+		case "integer":
+			defs[i] = Is("integer", func(path Path, v interface{}) *Results {
+				f, ok := toFloat64(v)
+				if !ok || math.Trunc(f) != f {
+					return SimpleResult(path, false, "Expected an integer, got %#v (%T)", v, v)
+				}
+				return ValidResult(path)
+			})
+		case "null":
+			defs[i] = IsNil
+		case "object":
+			defs[i] = Is("object", func(path Path, v interface{}) *Results {
+				if _, errorResults := isMapCheck(path, v); errorResults != nil {
+					return errorResults
+				}
+				return ValidResult(path)
+			})
+		case "array":
+			defs[i] = Is("array", func(path Path, v interface{}) *Results {
+				if _, errorResults := isSliceCheck(path, v); errorResults != nil {
+					return errorResults
+				}
+				return ValidResult(path)
+			})
+		default:
+			return IsDef{}, fmt.Errorf("unknown type %#v at %s", name, location)
+		}
+	}
+
+	if len(defs) == 1 {
+		return defs[0], nil
+	}
+	return IsAny(defs...), nil
+}
+
+func jsonSchemaProperties(properties interface{}, required interface{}, location string) (IsDef, error) {
+	props, ok := properties.(map[string]interface{})
+	if !ok {
+		return IsDef{}, fmt.Errorf("expected object for properties at %s, got %#v", location, properties)
+	}
+
+	var requiredKeys []string
+	if required != nil {
+		list, ok := required.([]interface{})
+		if !ok {
+			return IsDef{}, fmt.Errorf("expected list for required at %s, got %#v", location, required)
+		}
+		for _, k := range list {
+			key, ok := k.(string)
+			if !ok {
+				return IsDef{}, fmt.Errorf("expected string in required at %s, got %#v", location, k)
+			}
+			requiredKeys = append(requiredKeys, key)
+		}
+	}
+
+	propDefs := make(map[string]IsDef, len(props))
+	for key, prop := range props {
+		def, err := jsonSchemaIsDef(prop, location+"/properties/"+jsonPointerEscape(key))
+		if err != nil {
+			return IsDef{}, err
+		}
+		propDefs[key] = def
+	}
+
+	return Is("properties", func(path Path, v interface{}) *Results {
+		m, errorResults := isMapCheck(path, v)
+		if errorResults != nil {
+			// Properties only constrain objects
+			return ValidResult(path)
+		}
+
+		results := ValidResult(path)
+		for _, key := range requiredKeys {
+			if _, exists := m[key]; !exists {
+				results.merge(KeyMissingResult(path.ExtendMap(key)))
+			}
+		}
+		for key, def := range propDefs {
+			if value, exists := m[key]; exists {
+				results.merge(def.Check(path.ExtendMap(key), value, true))
+			}
+		}
+		return results
+	}), nil
+}
+
+func jsonSchemaItems(value interface{}, location string) (IsDef, error) {
+	if _, isList := value.([]interface{}); isList {
+		return IsDef{}, UnsupportedJSONSchemaKeyword{"items (list form)", location}
+	}
+
+	elemDef, err := jsonSchemaIsDef(value, location+"/items")
+	if err != nil {
+		return IsDef{}, err
+	}
+
+	sliceOf := IsSliceOf(elemDef)
+	return Is("items", func(path Path, v interface{}) *Results {
+		if _, errorResults := isSliceCheck(path, v); errorResults != nil {
+			// Items only constrain arrays
+			return ValidResult(path)
+		}
+		return sliceOf.Check(path, v, true)
+	}), nil
+}
+
+func jsonSchemaBound(keyword string, value interface{}, location string) (IsDef, error) {
+	bound, ok := toFloat64(value)
+	if !ok {
+		return IsDef{}, fmt.Errorf("expected number for %s at %s, got %#v", keyword, location, value)
+	}
+
+	def := IsGTE(bound)
+	if keyword == "maximum" {
+		def = IsLTE(bound)
+	}
+
+	return Is(keyword, func(path Path, v interface{}) *Results {
+		if _, isNum := toFloat64(v); !isNum {
+			// Bounds only constrain numbers
+			return ValidResult(path)
+		}
+		return def.Check(path, v, true)
+	}), nil
+}
+
+// jsonPointerEscape escapes a key for use in a JSON pointer, per RFC 6901.
+func jsonPointerEscape(key string) string {
+	return strings.Replace(strings.Replace(key, "~", "~0", -1), "/", "~1", -1)
+}
@@ -0,0 +1,182 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lookslike
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// optionalTag is the value of the lookslike struct tag marking a field as Optional for CompileStruct.
+const optionalTag = "optional"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// CompileStruct compiles a Validator from the type of the given struct, or pointer to a struct, so that a
+// model and its schema can share one definition. The resulting Validator checks data shaped like the JSON
+// encoding of the struct, such as a Map decoded from JSON, not struct values themselves.
+//
+// Only exported fields are considered. Keys are taken from the field's json tag, falling back to the field
+// name, and fields tagged json:"-" are skipped. Embedded structs without a json name have their fields
+// promoted, as encoding/json does. Fields tagged lookslike:"optional" may be absent.
+//
+// IsDefs are derived from field types: strings use IsString, bools use IsBool, numeric kinds use IsNumeric,
+// time.Time accepts either a time.Time or an RFC3339 string, and interface{} fields accept any value.
+// Nested structs, slices, arrays, and maps with string keys recurse into their element types, and pointers
+// accept either nil or a value matching the type they point to. Recursive types, such as a struct with a slice
+// of itself for its children, check nested values with the Validator of the enclosing type. Any other type
+// produces an error.
+func CompileStruct(v interface{}) (Validator, error) {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Cannot compile struct definition from %v (%T). Expected a struct or pointer to a struct", v, v)
+	}
+
+	c := structCompiler{validators: map[reflect.Type]*Validator{}}
+	schema, err := c.structSchema(t)
+	if err != nil {
+		return nil, err
+	}
+	return Compile(schema)
+}
+
+// structCompiler derives the schemas of struct types for CompileStruct.
+type structCompiler struct {
+	// validators holds the Validator of every struct type seen so far. A type's Validator is only set once its
+	// schema is compiled, so IsDefs for a type recurring within its own fields look it up when they're checked.
+	validators map[reflect.Type]*Validator
+}
+
+// structSchema builds a Map with an IsDef for each of the fields of the given struct type.
+func (c structCompiler) structSchema(t reflect.Type) (Map, error) {
+	schema := Map{}
+	promoted := Map{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, skip := structFieldKey(f)
+		if skip {
+			continue
+		}
+
+		if f.Anonymous && f.Tag.Get("json") == "" && f.Type.Kind() == reflect.Struct {
+			embedded, err := c.structSchema(f.Type)
+			if err != nil {
+				return nil, err
+			}
+			for k, v := range embedded {
+				promoted[k] = v
+			}
+			continue
+		}
+
+		id, err := c.typeIsDef(f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s.%s: %v", t.Name(), f.Name, err)
+		}
+		if f.Tag.Get("lookslike") == optionalTag {
+			id = Optional(id)
+		}
+		schema[key] = id
+	}
+
+	// Fields of the outer struct win over promoted ones, as with encoding/json
+	for k, v := range promoted {
+		if _, exists := schema[k]; !exists {
+			schema[k] = v
+		}
+	}
+	return schema, nil
+}
+
+// typeIsDef derives an IsDef matching the JSON encoding of values of the given type.
+func (c structCompiler) typeIsDef(t reflect.Type) (IsDef, error) {
+	if t == timeType {
+		return IsAny(IsType(time.Time{}), IsRFC3339), nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return IsString, nil
+	case reflect.Bool:
+		return IsBool, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return IsNumeric, nil
+	case reflect.Interface:
+		return Is("any value", func(path Path, v interface{}) *Results {
+			return ValidResult(path)
+		}), nil
+	case reflect.Ptr:
+		elemDef, err := c.typeIsDef(t.Elem())
+		if err != nil {
+			return IsDef{}, err
+		}
+		return Is("nil or "+elemDef.Name, func(path Path, v interface{}) *Results {
+			if isNil(v) {
+				return ValidResult(path)
+			}
+			return elemDef.Check(path, v, true)
+		}), nil
+	case reflect.Slice, reflect.Array:
+		elemDef, err := c.typeIsDef(t.Elem())
+		if err != nil {
+			return IsDef{}, err
+		}
+		return IsSliceOf(elemDef), nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return IsDef{}, fmt.Errorf("cannot derive an IsDef for map type %v without string keys", t)
+		}
+		elemDef, err := c.typeIsDef(t.Elem())
+		if err != nil {
+			return IsDef{}, err
+		}
+		return IsMapOf(elemDef), nil
+	case reflect.Struct:
+		validator, seen := c.validators[t]
+		if !seen {
+			validator = new(Validator)
+			c.validators[t] = validator
+
+			schema, err := c.structSchema(t)
+			if err != nil {
+				return IsDef{}, err
+			}
+			if *validator, err = Compile(schema); err != nil {
+				return IsDef{}, err
+			}
+		}
+		return Is("struct "+t.Name(), func(path Path, v interface{}) *Results {
+			m, errorResults := isMapCheck(path, v)
+			if errorResults != nil {
+				return errorResults
+			}
+
+			results := NewResults()
+			results.MergeUnderPrefix(path, (*validator)(m))
+			return results
+		}), nil
+	default:
+		return IsDef{}, fmt.Errorf("cannot derive an IsDef for type %v", t)
+	}
+}
@@ -17,6 +17,8 @@
 
 package lookslike
 
+import "sort"
+
 type flatValidator struct {
 	path  Path
 	isDef IsDef
@@ -29,14 +31,93 @@ type CompiledSchema []flatValidator
 func (cs CompiledSchema) Check(actual interface{}) *Results {
 	results := NewResults()
 	for _, pv := range cs {
-		actualV, actualKeyExists := pv.path.GetFrom(actual)
+		// Paths with wildcards are checked once per concrete path they expand to. An empty collection has none
+		// to check, but a missing one is missing, so unless the IsDef is Optional it fails where the path ends.
+		expanded, missing := pv.path.expandWildcardsReportingMissing(actual)
+		if !pv.isDef.Optional {
+			for _, path := range missing {
+				results.merge(pv.isDef.Check(path, nil, false))
+			}
+		}
+		present := false
+		for _, path := range expanded {
+			actualV, actualKeyExists := path.GetFrom(actual)
+			present = present || actualKeyExists
+			if isNilPointer(actualV) {
+				// A nil pointer is nil to the IsDef, and is absent as far as Optional IsDefs are concerned
+				actualV, actualKeyExists = nil, !pv.isDef.Optional
+			} else {
+				actualV = derefPointer(actualV)
+			}
 
-		if !pv.isDef.Optional || pv.isDef.Optional && actualKeyExists {
-			var checkRes *Results
-			checkRes = pv.isDef.Check(pv.path, actualV, actualKeyExists)
-			results.merge(checkRes)
+			if !pv.isDef.Optional || actualKeyExists || pv.isDef.hasDefault {
+				var checkRes *Results
+				checkRes = pv.isDef.Check(path, actualV, actualKeyExists)
+				results.merge(checkRes)
+			}
 		}
+		results.declare(pv.path.String(), present)
 	}
 
 	return results
 }
+
+// SchemaEntry describes a single check within a CompiledSchema.
+type SchemaEntry struct {
+	// Path is where the check applies, and may contain wildcards.
+	Path Path
+	// Name is the Name of the IsDef performing the check.
+	Name string
+	// Optional is true if the check is skipped when Path is absent.
+	Optional bool
+}
+
+// Entries returns a description of each check within the CompiledSchema, sorted by path so that the
+// entries of two schemas can be compared directly. Modifying the entries does not affect the schema.
+func (cs CompiledSchema) Entries() []SchemaEntry {
+	entries := make([]SchemaEntry, len(cs))
+	for i, fv := range cs {
+		path := make(Path, len(fv.path))
+		copy(path, fv.path)
+		entries[i] = SchemaEntry{path, fv.isDef.Name, fv.isDef.Optional}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Path.String() < entries[j].Path.String()
+	})
+	return entries
+}
+
+// CoverageReport describes which of the paths declared by a schema a value exercises. Paths are the declared
+// ones, so wildcard paths are reported once, as present if they match at least one path in the value.
+type CoverageReport struct {
+	// Checked lists the declared paths present in the value, which the schema checks.
+	Checked []string
+	// Absent lists the declared paths missing from the value, whose checks are skipped if they are Optional,
+	// and otherwise fail.
+	Absent []string
+}
+
+// Complete returns true if every path declared by the schema was present.
+func (cr CoverageReport) Complete() bool {
+	return len(cr.Absent) == 0
+}
+
+// Coverage reports which of the paths declared by the given schema are present in the given value, for
+// instance to verify that test fixtures exercise every field a schema declares. The schema is run against the
+// value, so IsDefs with side effects, such as IsUnique, see it as they would during validation. The declared
+// paths are those of the Maps and Slices compiled into the schema, including ones combined with Compose,
+// Strict, and the like, while Validators applied to nested values, such as with IsSliceOf(Ref(...)), aren't
+// covered. Both lists in the report are sorted.
+func Coverage(schema Validator, actual interface{}) CoverageReport {
+	report := CoverageReport{Checked: []string{}, Absent: []string{}}
+	for declared, present := range schema(actual).declared {
+		if present {
+			report.Checked = append(report.Checked, declared)
+		} else {
+			report.Absent = append(report.Absent, declared)
+		}
+	}
+	sort.Strings(report.Checked)
+	sort.Strings(report.Absent)
+	return report
+}
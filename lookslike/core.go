@@ -20,38 +20,193 @@ package lookslike
 import (
 	"errors"
 	"fmt"
-	"reflect"
 	"sort"
-	"strings"
+	"sync/atomic"
 )
 
+// isDefCount counts the IsDefs created by Is, so that each gets its own identity.
+var isDefCount uint64
+
 // Is creates a named IsDef with the given Checker.
 func Is(name string, checker ValueValidator) IsDef {
-	return IsDef{Name: name, Checker: checker}
+	return IsDef{Name: name, Checker: checker, identity: atomic.AddUint64(&isDefCount, 1)}
 }
 
-// Optional wraps an IsDef to mark the field's presence as Optional.
+// Optional wraps an IsDef to mark the field's presence as Optional. A missing key passes without the IsDef
+// being checked at all, and produces no result, while a present key must match the IsDef. Note that a key
+// that is present with a nil value is not missing, and is checked like any other value.
 func Optional(id IsDef) IsDef {
 	id.Name = "Optional " + id.Name
 	id.Optional = true
 	return id
 }
 
+// OptionalWithDefault is like Optional, but a missing key is treated as if it were present with the given
+// default value, rather than passing unchecked. This is useful for configuration where unspecified fields
+// take documented defaults, as it ensures the default itself satisfies the IsDef. Failures caused by the
+// default are prefixed with "default value", to tell them apart from failures of values that are present.
+func OptionalWithDefault(id IsDef, dflt interface{}) IsDef {
+	id = Optional(id)
+	id.Name = fmt.Sprintf("%s defaulting to %#v", id.Name, dflt)
+	id.hasDefault = true
+	id.defaultValue = dflt
+	return id
+}
+
+// WithMessage replaces the messages of the given IsDef's failures with the given one, which lets failures be
+// described in domain terms, such as "order total must be positive" rather than "expected value > 0". The
+// check itself is unchanged, and so are the results of values that pass. This includes failures due to a
+// missing key.
+func WithMessage(id IsDef, msg string) IsDef {
+	id.failureMessage = msg
+	return id
+}
+
+// AsWarning makes the given IsDef a soft constraint, whose failures are recorded as warnings rather than
+// failing validation, for checks that should be visible but not enforced, like a field that should usually be
+// present. A warning is a passing ValueResult whose Warning, like its Message, describes the failure, and
+// Results.Warnings lists them separately from failures.
+func AsWarning(id IsDef) IsDef {
+	id.warning = true
+	return id
+}
+
+// OptionalTree allows a nested object to be absent altogether, while validating it against the given schema
+// when it is present. Declaring the same object as a nested Map instead requires its children to be present,
+// and marking each child Optional would also accept an object missing required children. Only absence is
+// tolerated, so an object present with a nil value is validated, and fails, like any other non-map value.
+//
+// With Strict, an absent object produces no results and so nothing to report, while the keys of a present
+// object are checked against the schema, so unexpected keys within it fail as usual.
+// It panics if the schema can't be compiled, as MustCompile does.
+func OptionalTree(schema Map) IsDef {
+	validator := MustCompile(schema)
+	return Optional(Is("tree", func(path Path, v interface{}) *Results {
+		// Record a result for the object itself, so Strict counts it as tested even if all its children are
+		// Optional and absent
+		results := ValidResult(path)
+		results.MergeUnderPrefix(path, validator(v))
+		return results
+	}))
+}
+
+// Not inverts the given IsDef, passing when it fails and failing when it passes.
+// If the given IsDef is Optional the inverted one is as well, so a missing key still passes.
+func Not(id IsDef) IsDef {
+	not := Is("not "+id.Name, func(path Path, v interface{}) *Results {
+		if id.Check(path, v, true).Valid {
+			return SimpleResult(path, false, "expected NOT %s, but value %#v matched", id.Name, v)
+		}
+
+		return ValidResult(path)
+	})
+	not.Optional = id.Optional
+	return not
+}
+
 // Map is the type used to define schema definitions for Compile and to represent an arbitrary
 // map of values of any type.
 type Map map[string]interface{}
 
+// MergeMaps deep merges two schema definitions, so that a schema can be assembled from shared fragments
+// before being compiled. Keys of overlay take precedence over those of base, except that where both hold a
+// map, whether a Map or a map[string]interface{}, the two are merged recursively. Any other value in overlay,
+// such as an IsDef, replaces the base value entirely, and an overlay map likewise replaces a base value
+// that's not a map, so a key holding an IsDef in one and a nested Map in the other takes overlay's.
+// Keys are compared as written, so a dotted key such as "a.b" is not merged with a nested Map under "a".
+// Neither argument is modified, and the maps within the result are copies, while other values are shared.
+func MergeMaps(base, overlay Map) Map {
+	merged := make(Map, len(base)+len(overlay))
+	for k, v := range base {
+		merged[k] = copyNestedMaps(v)
+	}
+	for k, v := range overlay {
+		overlayMap, overlayIsMap := asMap(v)
+		baseMap, baseIsMap := asMap(merged[k])
+		if overlayIsMap && baseIsMap {
+			merged[k] = MergeMaps(baseMap, overlayMap)
+		} else {
+			merged[k] = copyNestedMaps(v)
+		}
+	}
+	return merged
+}
+
+// asMap returns the given value as a Map if it is a Map or a map[string]interface{}.
+func asMap(v interface{}) (Map, bool) {
+	switch typed := v.(type) {
+	case Map:
+		return typed, true
+	case map[string]interface{}:
+		return Map(typed), true
+	default:
+		return nil, false
+	}
+}
+
+// copyNestedMaps copies the given value if it is a map, along with any maps nested within it.
+func copyNestedMaps(v interface{}) interface{} {
+	m, isMap := asMap(v)
+	if !isMap {
+		return v
+	}
+	return MergeMaps(m, nil)
+}
+
 // Slice is a convenience []interface{} used to declare schema defs. You would typically nest this inside
 // a Map as a value, and it would be able to match against any type of non-empty slice.
 type Slice []interface{}
 
+// LaxSlice is like Slice, but when compiled as the root of a schema, it only checks the elements it
+// specifies, so the actual slice may have additional trailing elements, as with logs that grow over time but
+// have a stable prefix. Root Slices are strict by default since it would be surprising for a schema listing
+// every element to pass when there are more of them. Slices nested in a Map are never strict on their own,
+// wrap the Validator with Strict for that, so LaxSlice only differs from Slice at the root.
+type LaxSlice []interface{}
+
 // Catchall type for things that aren't assertable to either Map or Slice.
 type Scalar interface{}
 
 // Validator is the result of Compile and is run against the map you'd like to test.
 type Validator func(interface{}) *Results
 
-// Compose combines multiple SchemaValidators into a single one.
+// Required returns a Validator that only checks that each of the given keys is present, with any value
+// including nil, reporting each missing key at its own path. It's a minimal presence check to combine with
+// value checks using Compose. Keys are paths, so "a.b" requires the key b within a, and wildcards such as
+// "items.[*].id" require the key in every element. It panics if a key is not a valid path.
+func Required(keys ...string) Validator {
+	schema := Map{}
+	for _, k := range keys {
+		schema[k] = KeyPresent
+	}
+	return MustCompile(schema)
+}
+
+// ValidateAt runs the given Validator against the value at the given path of doc, rather than against the whole
+// document, reporting its results beneath the path. This reuses a schema for a component, such as an address,
+// to validate a nested section of a document. If the path contains wildcards every value it matches is
+// validated. If no value is found at the path the result is a single KeyMissingVR failure at the path.
+func ValidateAt(path Path, v Validator, doc interface{}) *Results {
+	results := NewResults()
+	found := false
+	for _, concrete := range path.expandWildcards(doc) {
+		value, exists := concrete.GetFrom(doc)
+		if !exists {
+			continue
+		}
+		found = true
+		results.MergeUnderPrefix(concrete, v(value))
+	}
+
+	if !found {
+		return KeyMissingResult(path)
+	}
+	return results
+}
+
+// Compose combines multiple SchemaValidators into a single one. Their Results are merged in the order the
+// validators are given, as described by Results.Merge, so where several check the same path a failure
+// from any of them makes it invalid.
 func Compose(validators ...Validator) Validator {
 	return func(actual interface{}) *Results {
 		results := make([]*Results, len(validators))
@@ -61,73 +216,410 @@ func Compose(validators ...Validator) Validator {
 
 		combined := NewResults()
 		for _, r := range results {
-			r.EachResult(func(path Path, vr ValueResult) bool {
-				combined.record(path, vr)
-				return true
-			})
+			combined.merge(r)
+		}
+		return combined
+	}
+}
+
+// ComposeStrict is like Compose, but also reports contradictions between the validators: whenever one of them
+// passes a path that another fails, an additional failure saying "conflicting assertions on path" is recorded
+// at that path, naming the validators by their position in the arguments. This helps catch mistakes in
+// schemas built from several parts, which Compose would silently report as a plain failure.
+func ComposeStrict(validators ...Validator) Validator {
+	return func(actual interface{}) *Results {
+		combined := NewResults()
+		passedBy := map[string][]int{}
+		failedBy := map[string][]int{}
+		for idx, validator := range validators {
+			// Each validator is only run once, since IsDefs like IsUnique have side effects
+			results := validator(actual)
+			combined.merge(results)
+			for path, vrs := range results.Fields {
+				passed, failed := false, false
+				for _, vr := range vrs {
+					passed = passed || vr.Valid
+					failed = failed || !vr.Valid
+				}
+				if passed {
+					passedBy[path] = append(passedBy[path], idx)
+				}
+				if failed {
+					failedBy[path] = append(failedBy[path], idx)
+				}
+			}
+		}
+
+		for path, failed := range failedBy {
+			if passed, ok := passedBy[path]; ok {
+				combined.record(MustParsePath(path), ValueResult{
+					Valid: false,
+					Message: fmt.Sprintf(
+						"conflicting assertions on path %s: validators %v passed, but validators %v failed",
+						path, passed, failed,
+					),
+				})
+			}
 		}
 		return combined
 	}
 }
 
+// Check creates a Validator from a function that receives the whole document, for assertions about the
+// relationships between fields, such as an end date being after a start date, which IsDefs can't make since
+// each only sees its own value. The function should record its results at the paths they concern, which
+// Path.GetFrom helps with, and may return nil if it has nothing to report. Combine it with structural checks
+// using Compose.
+func Check(fn func(doc interface{}) *Results) Validator {
+	return func(actual interface{}) *Results {
+		results := fn(actual)
+		if results == nil {
+			return NewResults()
+		}
+		return results
+	}
+}
+
+// When applies the given Validator to the whole document only if the value at the given path matches cond,
+// passing otherwise. This allows conditional sub-schemas, such as requiring a config key to be a string when
+// the type key is "a". A missing key only matches conditions that permit one, such as KeyMissing or Optional
+// IsDefs. Failures name the condition that applied. Use Not(cond) for the opposite case, or Discriminate
+// when choosing between several sub-schemas based on the value of one key.
+func When(path Path, cond IsDef, then Validator) Validator {
+	return func(actual interface{}) *Results {
+		v, exists := path.GetFrom(actual)
+		if !cond.Check(path, v, exists).Valid {
+			return NewResults()
+		}
+		return then(actual).withMessagePrefix(fmt.Sprintf("when %s %s: ", path, cond.Name))
+	}
+}
+
+// Discriminate validates documents whose shape depends on the value of a single key, often called a
+// discriminated union. The string at the given key selects the Validator from schemas to apply to the whole
+// document. Documents that lack the key, or whose value at the key is not one of the known ones, fail at the
+// key with a message listing the known values. The key itself is recorded as valid when it matches, so
+// Strict does not consider it unexpected.
+func Discriminate(key string, schemas map[string]Validator) Validator {
+	path := Path{}.ExtendMap(key)
+	known := make([]string, 0, len(schemas))
+	for k := range schemas {
+		known = append(known, k)
+	}
+	sort.Strings(known)
+
+	return func(actual interface{}) *Results {
+		v, exists := path.GetFrom(actual)
+		if !exists {
+			return SimpleResult(path, false, "expected discriminant key %s to be present, with one of the values %v", path, known)
+		}
+
+		discriminant, isStr := v.(string)
+		validator, ok := schemas[discriminant]
+		if !isStr || !ok {
+			return SimpleResult(path, false, "unknown discriminant value %#v, expected one of %v", v, known)
+		}
+
+		results := ValidResult(path)
+		results.merge(validator(actual))
+		return results
+	}
+}
+
+// IsEqualToDocument creates a Validator comparing the whole document against the expected one, such as a
+// fixture, reporting each difference at the path it occurs at rather than as a single mismatch. Missing and
+// unexpected keys, and slices of differing lengths, are reported as such. Numbers of any type are compared
+// numerically, so the float64 values produced by decoding JSON match integer fixtures, but values of other
+// differing types are reported as type mismatches, so 5 does not match "5". Other values are compared with
+// IsEqual.
+func IsEqualToDocument(expected interface{}) Validator {
+	return func(actual interface{}) *Results {
+		results := NewResults()
+		compareDocument(Path{}, Path{}, actual, expected, nil, results)
+		return results
+	}
+}
+
+// WithCoercionWarnings records a Warning on each result of the given Validator that only passed after the
+// value was coerced to another type, such as IsGT comparing an int as a float64, or IsEqual comparing a
+// json.Number to an int. This helps tighten fixtures over time, since the warnings don't affect whether the
+// validation passes. See Results.Warnings.
+func WithCoercionWarnings(validator Validator) Validator {
+	return func(actual interface{}) *Results {
+		results := validator(actual)
+		for path, notes := range results.notes {
+			valueResults := results.Fields[path]
+			for idx, note := range notes {
+				if note.coercion != "" && valueResults[idx].Warning == "" {
+					valueResults[idx].Warning = note.coercion
+				}
+			}
+		}
+		return results
+	}
+}
+
+// WithIsDefNames sets IsDefName on each passing result of the given Validator to the Name of the IsDef that
+// validated the value, the innermost one if they're nested, so that tooling can report which fields were
+// asserted and by what. Without it IsDefName is left empty, so passing results still equal ValidVR.
+func WithIsDefNames(validator Validator) Validator {
+	return func(actual interface{}) *Results {
+		results := validator(actual)
+		for path, notes := range results.notes {
+			valueResults := results.Fields[path]
+			for idx, note := range notes {
+				if note.isDefName != "" && valueResults[idx].Valid {
+					valueResults[idx].IsDefName = note.isDefName
+				}
+			}
+		}
+		return results
+	}
+}
+
 // Strict is used when you want any unspecified keys that are encountered to be considered errors.
 func Strict(laxValidator Validator) Validator {
+	return strictAt(Path{}, laxValidator)
+}
+
+// StrictAt is like Strict, but only considers unspecified keys beneath the given path to be errors.
+// Elsewhere in the document unspecified keys are permitted, as they would be without Strict.
+// The key at the path itself is not required to be present.
+func StrictAt(prefix Path, laxValidator Validator) Validator {
+	return strictAt(prefix, laxValidator)
+}
+
+func strictAt(prefix Path, laxValidator Validator) Validator {
 	return func(actual interface{}) *Results {
 		results := laxValidator(actual)
 
-		// The inner workings of this are a little weird
-		// We use a hash of dotted paths to track the results
-		// We can Check if a key had a test associated with it by looking up the laxValidator
-		// result data
+		// We use the flattened hash of dotted paths in the results to track which keys were tested.
 		// What's trickier is intermediate maps, maps don't usually have explicit tests, they usually just have
-		// their properties tested.
-		// This method counts an intermediate map as tested if a subkey is tested.
-		// Since the datastructure we have to search is a flattened hashmap of the original map we take that hashmap
-		// and turn it into a sorted string array, then do a binary prefix search to determine if a subkey was tested.
-		// It's a little weird, but is fairly efficient. We could stop using the flattened map as a datastructure, but
-		// that would add complexity elsewhere. Probably a good refactor at some point, but not worth it now.
-		validatedPaths := []string{}
+		// their properties tested. An intermediate map counts as tested if a subkey is tested, so we also
+		// collect the ancestors of every tested path into a set, making both checks a single lookup.
+		testedAncestors := map[string]bool{}
 		for k := range results.Fields {
-			validatedPaths = append(validatedPaths, k)
+			eachPathStringAncestor(k, func(ancestor string) {
+				testedAncestors[ancestor] = true
+			})
 		}
-		sort.Strings(validatedPaths)
 
-		walk(actual, false, func(woi walkObserverInfo) error {
-			_, validatedExactly := results.Fields[woi.path.String()]
-			if validatedExactly {
-				return nil // This key was tested, passes strict test
+		beneathPrefix := func(path Path) bool {
+			return len(path) > len(prefix) && path.HasPrefix(prefix)
+		}
+		observe := func(woi walkObserverInfo) error {
+			if !beneathPrefix(woi.path) {
+				return nil // Not beneath the path strictness applies to
 			}
 
-			// Search returns the point just before an actual match (since we ruled out an exact match with the cheaper
-			// hash Check above. We have to validate the actual match with a prefix Check as well
-			matchIdx := sort.SearchStrings(validatedPaths, woi.path.String())
-			if matchIdx < len(validatedPaths) && strings.HasPrefix(validatedPaths[matchIdx], woi.path.String()) {
-				return nil
+			pathStr := woi.path.String()
+			if _, validatedExactly := results.Fields[pathStr]; validatedExactly {
+				if _, isStruct := structMap(woi.value); isStruct && !testedAncestors[pathStr] {
+					// A struct checked as a whole, such as by IsEqual, with none of its fields checked
+					// individually, is treated as a value, not an object whose fields are unexpected
+					return errSkipChildren
+				}
+				return nil // This key was tested, passes strict test
+			}
+			if testedAncestors[pathStr] {
+				return nil // A subkey was tested
+			}
+			if isNilPointer(woi.value) {
+				return nil // Nil pointers are absent, as they are to Optional IsDefs
 			}
 
 			results.merge(StrictFailureResult(woi.path))
 
 			return nil
+		}
+
+		walk(actual, false, func(woi walkObserverInfo) error {
+			err := observe(woi)
+			if woi.cycle && err == nil && beneathPrefix(woi.path) {
+				// The children of a value within its own descendants aren't walked, so rather than leave them
+				// unchecked the cycle is reported, while the rest of the document is still walked
+				results.merge(SimpleResult(woi.path, false, "cycle detected at path %s", woi.path))
+			}
+			return err
 		})
 
 		return results
 	}
 }
 
+// StrictWithSuggestions is like Strict, but when an unexpected key closely resembles a key the schema
+// checked at the same level, the failure suggests it, e.g. "did you mean 'name'?". This helps spot
+// typos in both data and schemas. Keys within an edit distance of 2 are considered close.
+func StrictWithSuggestions(laxValidator Validator) Validator {
+	strict := Strict(laxValidator)
+	return func(actual interface{}) *Results {
+		results := strict(actual)
+
+		var expected, unexpected []Path
+		for k, vrs := range results.Fields {
+			parsed, err := ParsePath(k)
+			if err != nil {
+				continue
+			}
+			if len(vrs) == 1 && vrs[0] == StrictFailureVR {
+				unexpected = append(unexpected, parsed)
+			} else {
+				expected = append(expected, parsed)
+			}
+		}
+
+		for _, path := range unexpected {
+			last := path.Last()
+			if last.Type != pcMapKey {
+				continue
+			}
+
+			parentLen := len(path) - 1
+			best, bestDistance := "", maxSuggestionDistance+1
+			for _, e := range expected {
+				if len(e) <= parentLen || e[parentLen].Type != pcMapKey || !e.HasPrefix(path.Parent()) {
+					continue
+				}
+				candidate := e[parentLen].Key
+				if d := levenshtein(last.Key, candidate); d < bestDistance || (d == bestDistance && candidate < best) {
+					best, bestDistance = candidate, d
+				}
+			}
+
+			if best != "" {
+				results.Fields[path.String()] = []ValueResult{{
+					Valid:   false,
+					Message: fmt.Sprintf("%s: unexpected key '%s', did you mean '%s'?", StrictFailureVR.Message, last.Key, best),
+				}}
+			}
+		}
+
+		return results
+	}
+}
+
+// maxSuggestionDistance is the largest edit distance at which StrictWithSuggestions will suggest a key.
+const maxSuggestionDistance = 2
+
+// levenshtein computes the edit distance between two strings.
+func levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	cur := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		cur[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(br)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// Limit caps the number of failures recorded in the Results of the given Validator at max, which keeps
+// output readable when validating large documents against a badly mismatched schema. Failures beyond
+// the limit are counted in Results.Truncated rather than recorded. Which failures are kept is deterministic,
+// those with the lexically smallest paths win. Passing results are never dropped.
+func Limit(validator Validator, max int) Validator {
+	return func(actual interface{}) *Results {
+		full := validator(actual)
+
+		paths := make([]string, 0, len(full.Fields))
+		for path := range full.Fields {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		limited := NewResults()
+		limited.maxFailures = max
+		limited.Truncated = full.Truncated
+		limited.mergeDeclared(full)
+		for _, path := range paths {
+			for idx, vr := range full.Fields[path] {
+				limited.recordFrom(path, vr, full, path, idx)
+			}
+		}
+
+		return limited
+	}
+}
+
+// Compile compiles a schema definition into a Validator. The definition may be a Map, a Slice, a LaxSlice, or
+// an IsDef.
+// The plain map[string]interface{} and []interface{} types are accepted as well, and are treated exactly
+// like Map and Slice, which is convenient when building schemas programmatically.
 func Compile(in interface{}) (validator Validator, err error) {
 	switch in.(type) {
 	case Map:
 		return compileMap(in.(Map))
+	case map[string]interface{}:
+		return compileMap(Map(in.(map[string]interface{})))
 	case Slice:
 		return compileSlice(in.(Slice))
+	case []interface{}:
+		return compileSlice(Slice(in.([]interface{})))
+	case LaxSlice:
+		return compileLaxSlice(Slice(in.(LaxSlice)))
 	case IsDef:
 		return compileIsDef(in.(IsDef))
 	default:
-		msg := fmt.Sprintf("Cannot compile definition from %v (%T). Expected one of 'Map', 'Slice', or 'IsDef'", in, in)
-		return nil, errors.New(msg)
+		return nil, unsupportedDefinitionError(in)
 	}
 }
 
+// CompileSchema compiles a schema definition, accepting the same types as Compile, into a CompiledSchema
+// rather than a Validator, so that what it checks can be inspected with CompiledSchema.Entries, for instance
+// to document a schema or to diff two versions of it. Slices are compiled as they would be within a Map, so
+// the strictness Compile applies to a top level Slice isn't part of the result.
+func CompileSchema(in interface{}) (schema CompiledSchema, err error) {
+	wo, compiled := setupWalkObserver()
+	switch typed := in.(type) {
+	case Map:
+		err = walkMap(typed, true, wo)
+	case map[string]interface{}:
+		err = walkMap(Map(typed), true, wo)
+	case Slice:
+		err = walkSlice(typed, true, wo)
+	case []interface{}:
+		err = walkSlice(Slice(typed), true, wo)
+	case LaxSlice:
+		err = walkSlice(Slice(typed), true, wo)
+	case IsDef:
+		return CompiledSchema{{Path{}, typed}}, nil
+	default:
+		return nil, unsupportedDefinitionError(in)
+	}
+	return *compiled, err
+}
+
+func unsupportedDefinitionError(in interface{}) error {
+	msg := fmt.Sprintf(
+		"Cannot compile definition from %v (%T). Expected one of 'Map', 'Slice', 'LaxSlice', 'IsDef', "+
+			"'map[string]interface{}', or '[]interface{}'",
+		in, in,
+	)
+	return errors.New(msg)
+}
+
 func compileMap(in Map) (validator Validator, err error) {
 	wo, compiled := setupWalkObserver()
 	err = walkMap(in, true, wo)
@@ -138,19 +630,28 @@ func compileMap(in Map) (validator Validator, err error) {
 }
 
 func compileSlice(in Slice) (validator Validator, err error) {
+	lax, err := compileLaxSlice(in)
+
+	// Slices are strict in validation unless they're a LaxSlice because
+	// it would be surprising to only validate the first specified values
+	return Strict(lax), err
+}
+
+func compileLaxSlice(in Slice) (validator Validator, err error) {
 	wo, compiled := setupWalkObserver()
 	err = walkSlice(in, true, wo)
 
-	// Slices are always strict in validation because
-	// it would be surprising to only validate the first specified values
-	return Strict(func(actual interface{}) *Results {
+	return func(actual interface{}) *Results {
 		return compiled.Check(actual)
-	}), err
+	}, err
 }
 
 func compileIsDef(def IsDef) (validator Validator, err error) {
 	return func(actual interface{}) *Results {
-		return def.Check(Path{}, actual, true)
+		if isNilPointer(actual) {
+			actual = nil
+		}
+		return def.Check(Path{}, derefPointer(actual), true)
 	}, nil
 }
 
@@ -160,10 +661,8 @@ func setupWalkObserver() (walkObserver, *CompiledSchema) {
 		// Determine whether we should test this value
 		// We want to test all values except collections that contain a value
 		// If a collection contains a value, we Check those 'leaf' values instead
-		rv := reflect.ValueOf(current.value)
-		kind := rv.Kind()
-		isCollection := kind == reflect.Map || kind == reflect.Slice
-		isNonEmptyCollection := isCollection && rv.Len() > 0
+		length, isCollection := collectionLen(current.value)
+		isNonEmptyCollection := isCollection && length > 0
 
 		if !isNonEmptyCollection {
 			isDef, isIsDef := current.value.(IsDef)
@@ -171,7 +670,18 @@ func setupWalkObserver() (walkObserver, *CompiledSchema) {
 				isDef = IsEqual(current.value)
 			}
 
-			compiled = append(compiled, flatValidator{current.path, isDef})
+			// The walk reuses the path's backing array, so it must be copied to be kept
+			path := make(Path, len(current.path))
+			copy(path, current.path)
+			compiled = append(compiled, flatValidator{path, isDef})
+
+			// Leaves of a schema are values to check, so structs such as IsDefs aren't traversed
+			return errSkipChildren
+		}
+		if current.cycle {
+			path := make(Path, len(current.path))
+			copy(path, current.path)
+			return cycleError{path: path}
 		}
 		return nil
 	}, &compiled
@@ -185,3 +695,23 @@ func MustCompile(in interface{}) Validator {
 	}
 	return compiled
 }
+
+// CompileRecursive creates a Validator for self-referential structures, such as comment threads where each
+// comment contains a list of replies that are themselves comments. The build function receives a Validator
+// standing in for the one being built, which it can apply to nested values with Ref, and returns the
+// Validator to use, e.g.:
+//
+//	CompileRecursive(func(self Validator) Validator {
+//		return MustCompile(Map{"text": IsString, "replies": IsSliceOf(Ref(self))})
+//	})
+//
+// Since self is only invoked on the values it is applied to, recursion is bounded by the depth of the data
+// being validated, not by the schema. The build function is called once, and must not invoke self itself.
+func CompileRecursive(build func(self Validator) Validator) Validator {
+	var built Validator
+	self := func(actual interface{}) *Results {
+		return built(actual)
+	}
+	built = build(self)
+	return built
+}
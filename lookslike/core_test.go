@@ -18,11 +18,17 @@
 package lookslike
 
 import (
+	"encoding/json"
+	"fmt"
+	"reflect"
 	"regexp"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func assertValidator(t *testing.T, validator Validator, input Map) {
@@ -71,6 +77,70 @@ func TestBadFlat(t *testing.T) {
 	assert.Equal(t, result, KeyMissingVR)
 }
 
+func TestCheckerPanic(t *testing.T) {
+	panicky := Is("panicky", func(path Path, v interface{}) *Results {
+		return SimpleResult(path, v.(string) == "a", "is a")
+	})
+
+	res := MustCompile(Map{"a": panicky, "b": "x"})(Map{"a": 1, "b": "x"})
+	assert.False(t, res.Valid)
+	assert.Len(t, res.ErrorMessages(), 1)
+	assert.Contains(t, res.Fields["a"][0].Message, "panicky panicked while checking a int")
+
+	assertResults(t, MustCompile(Map{"a": panicky})(Map{"a": "a"}))
+}
+
+func TestUntypedInputs(t *testing.T) {
+	// A nil document is a value like any other
+	res := Strict(MustCompile(Map{"a": 1}))(nil)
+	assert.False(t, res.Valid)
+
+	// Maps with keys that aren't strings are keyed by their formatted keys
+	doc := Map{"codes": map[int]string{200: "ok"}}
+	assertResults(t, Strict(MustCompile(Map{"codes.200": "ok"}))(doc))
+}
+
+func TestStrictCycle(t *testing.T) {
+	doc := Map{"name": "foo"}
+	doc["parent"] = Map{"child": doc}
+
+	res := Strict(MustCompile(Map{"name": "foo", "parent.child.name": "foo"}))(doc)
+	assert.False(t, res.Valid)
+	assert.Equal(t, map[string]string{
+		"parent.child": "cycle detected at path parent.child",
+	}, res.ErrorMessages())
+}
+
+func TestStrictCycleBesideUnexpectedKeys(t *testing.T) {
+	doc := Map{"name": "foo", "a": 1, "b": 2, "c": 3, "d": 4, "e": 5}
+	doc["loop"] = doc
+
+	// The cycle doesn't stop the keys walked after it, in whatever order, from being checked
+	for i := 0; i < 20; i++ {
+		res := Strict(MustCompile(Map{"name": "foo"}))(doc)
+		assert.False(t, res.Valid)
+		var failed []string
+		for path := range res.ErrorMessages() {
+			failed = append(failed, path)
+		}
+		sort.Strings(failed)
+		assert.Equal(t, []string{"a", "b", "c", "d", "e", "loop"}, failed)
+		assert.Len(t, res.Fields["loop"], 2)
+	}
+}
+
+func TestStrictPointerCycle(t *testing.T) {
+	node := &testNode{Name: "a"}
+	node.Next = node
+
+	res := Strict(MustCompile(Map{"name": "a", "next.name": "a"}))(node)
+	assert.False(t, res.Valid)
+	assert.Equal(t, map[string]string{
+		"children": StrictFailureVR.Message,
+		"next":     "cycle detected at path next",
+	}, res.ErrorMessages())
+}
+
 func TestScalar(t *testing.T) {
 	results := MustCompile(IsEqual(42))(42)
 	assertResults(t, results)
@@ -140,6 +210,16 @@ func TestSliceStrictness(t *testing.T) {
 	assert.True(t, results.Fields["[2]"][0].Valid)
 }
 
+func TestLaxSlice(t *testing.T) {
+	validator := MustCompile(LaxSlice{"start", IsString})
+
+	assertResults(t, validator([]interface{}{"start", "a"}))
+	assertResults(t, validator([]interface{}{"start", "a", "b", 3}))
+	assert.False(t, validator([]interface{}{"start", 1, "b"}).Valid)
+	// Specified elements are still required
+	assert.False(t, validator([]interface{}{"start"}).Valid)
+}
+
 func TestPrimitiveSlice(t *testing.T) {
 	actual := []int{1, 1, 2, 3}
 	results := MustCompile(Slice{1, 1, 2, 3})(actual)
@@ -181,6 +261,88 @@ func TestNested(t *testing.T) {
 	assert.Len(t, results.Fields, 2, "One result per matcher")
 }
 
+func TestWildcards(t *testing.T) {
+	validator := MustCompile(Map{
+		"items.[*].price": IsNumeric,
+		"labels.*":        IsString,
+	})
+
+	good := Map{
+		"items":  []interface{}{Map{"price": 1}, Map{"price": 2.5}},
+		"labels": Map{"a": "b", "c": "d"},
+	}
+	res := validator(good)
+	assertResults(t, res)
+	assert.Len(t, res.Fields, 4)
+	assert.Contains(t, res.Fields, "items.[1].price")
+	assert.Contains(t, res.Fields, "labels.c")
+
+	// Strictness is enforced against the concrete paths
+	assertResults(t, Strict(validator)(good))
+
+	bad := Map{
+		"items":  []interface{}{Map{"price": "free"}, Map{"name": "no price"}},
+		"labels": Map{"a": 1},
+	}
+	res = validator(bad)
+	assert.False(t, res.Valid)
+	assert.False(t, res.Fields["items.[0].price"][0].Valid)
+	assert.Equal(t, KeyMissingVR, res.Fields["items.[1].price"][0])
+	assert.False(t, res.Fields["labels.a"][0].Valid)
+
+	// Empty collections have nothing to check
+	assertResults(t, validator(Map{"items": []interface{}{}, "labels": Map{}}))
+
+	// But missing ones are missing, where the path stops resolving
+	res = MustCompile(Map{"items.[*].id": IsString})(Map{})
+	assert.False(t, res.Valid)
+	assert.Equal(t, map[string][]ValueResult{"items": {KeyMissingVR}}, res.Fields)
+
+	res = MustCompile(Map{"order.items.[*].tags.[*]": IsString})(Map{"order": Map{
+		"items": []interface{}{Map{"tags": []interface{}{"a"}}, Map{}},
+	}})
+	assert.False(t, res.Valid)
+	assert.Equal(t, KeyMissingVR, res.Fields["order.items.[1].tags"][0])
+	assert.True(t, res.Fields["order.items.[0].tags.[0]"][0].Valid)
+
+	// Unless the IsDef is Optional
+	assertResults(t, MustCompile(Map{"items.[*].id": Optional(IsString)})(Map{}))
+}
+
+func TestNegativeIndex(t *testing.T) {
+	validator := MustCompile(Map{"events.[-1].type": "latest"})
+
+	assertValidator(t, validator, Map{"events": []Map{{"type": "old"}, {"type": "latest"}}})
+	assert.False(t, validator(Map{"events": []Map{{"type": "latest"}, {"type": "old"}}}).Valid)
+	assert.False(t, validator(Map{"events": []Map{}}).Valid)
+}
+
+func TestScalarComposition(t *testing.T) {
+	// Results for scalars are recorded at the empty root path, which must survive merging
+	res := Compose(MustCompile(IsEqual(42)), MustCompile(IsNumeric))(42)
+	assertResults(t, res)
+	assert.Len(t, res.Fields[""], 2)
+}
+
+func TestDottedKeys(t *testing.T) {
+	m := Map{
+		"http.response.status": 200,
+		"http":                 Map{"request": Map{"method": "GET"}},
+	}
+
+	validator := MustCompile(Map{
+		`["http.response.status"]`: 200,
+		"http.request.method":      "GET",
+	})
+
+	res := validator(m)
+	assertResults(t, res)
+	assert.Contains(t, res.Fields, `["http.response.status"]`)
+
+	// The literal dotted key is not confused with nested maps during strict checks
+	assertResults(t, Strict(validator)(m))
+}
+
 func TestComposition(t *testing.T) {
 	m := Map{
 		"foo": "bar",
@@ -212,6 +374,179 @@ func TestComposition(t *testing.T) {
 	assert.True(t, fakeT.Failed())
 }
 
+func TestComposeStrict(t *testing.T) {
+	m := Map{"foo": "bar", "baz": 1}
+
+	validator := ComposeStrict(
+		MustCompile(Map{"foo": IsString, "baz": 1}),
+		MustCompile(Map{"foo": "bar"}),
+	)
+	assertValidator(t, validator, m)
+
+	validator = ComposeStrict(
+		MustCompile(Map{"foo": IsString, "baz": 1}),
+		MustCompile(Map{"baz": IsNumeric}),
+		MustCompile(Map{"foo": "qux"}),
+	)
+	res := validator(m)
+	assert.False(t, res.Valid)
+	errs := res.DetailedErrors().Fields
+	assert.Len(t, errs, 1)
+	require.Len(t, errs["foo"], 2)
+	assert.Equal(t, "conflicting assertions on path foo: validators [0] passed, but validators [2] failed", errs["foo"][1].Message)
+
+	// Validators are only run once
+	assertValidator(t, ComposeStrict(MustCompile(Map{"foo": IsUnique()})), m)
+
+	// Failures that every validator agrees on aren't conflicts
+	res = validator(Map{"foo": 1, "baz": 1})
+	for _, vr := range res.Fields["foo"] {
+		assert.NotContains(t, vr.Message, "conflicting")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	startPath, endPath := MustParsePath("start"), MustParsePath("end")
+	ordered := Check(func(doc interface{}) *Results {
+		start, _ := startPath.GetFrom(doc)
+		end, _ := endPath.GetFrom(doc)
+		startT, startOk := start.(time.Time)
+		endT, endOk := end.(time.Time)
+		if !startOk || !endOk {
+			return nil
+		}
+		if !endT.After(startT) {
+			return SimpleResult(endPath, false, "expected end %v to be after start %v", endT, startT)
+		}
+		return ValidResult(endPath)
+	})
+	validator := Compose(MustCompile(Map{"start": IsType(time.Time{}), "end": IsType(time.Time{})}), ordered)
+
+	now := time.Now()
+	assertValidator(t, validator, Map{"start": now, "end": now.Add(time.Hour)})
+
+	res := validator(Map{"start": now, "end": now.Add(-time.Hour)})
+	assert.False(t, res.Valid)
+	assert.Len(t, res.DetailedErrors().Fields["end"], 1)
+
+	// A nil result from the function is valid
+	res = validator(Map{"start": "now", "end": now})
+	assert.False(t, res.Valid)
+	assert.Len(t, res.DetailedErrors().Fields, 1)
+	assert.True(t, ordered(Map{}).Valid)
+}
+
+func TestWhen(t *testing.T) {
+	typePath := MustParsePath("type")
+	validator := Compose(
+		When(typePath, IsEqual("a"), MustCompile(Map{"config": IsString})),
+		When(typePath, Not(IsEqual("a")), MustCompile(Map{"config": IsNumeric})),
+	)
+
+	assertValidator(t, validator, Map{"type": "a", "config": "x"})
+	assertValidator(t, validator, Map{"type": "b", "config": 1})
+
+	res := validator(Map{"type": "a", "config": 1})
+	assert.False(t, res.Valid)
+	msg := res.DetailedErrors().Fields["config"][0].Message
+	assert.Contains(t, msg, "when type equals: ")
+
+	// Conditions on missing keys only match if they permit absence
+	res = When(typePath, KeyMissing, MustCompile(Map{"config": "default"}))(Map{"config": "x"})
+	assert.False(t, res.Valid)
+	assert.True(t, When(typePath, IsString, MustCompile(Map{"config": "default"}))(Map{"config": "x"}).Valid)
+}
+
+func TestDiscriminate(t *testing.T) {
+	validator := Strict(Discriminate("kind", map[string]Validator{
+		"circle": MustCompile(Map{"radius": IsNumeric}),
+		"rect":   MustCompile(Map{"width": IsNumeric, "height": IsNumeric}),
+	}))
+
+	assertValidator(t, validator, Map{"kind": "circle", "radius": 2})
+	assertValidator(t, validator, Map{"kind": "rect", "width": 2, "height": 3})
+
+	res := validator(Map{"kind": "circle", "width": 2})
+	assert.False(t, res.Valid)
+	assert.Equal(t, KeyMissingVR, res.DetailedErrors().Fields["radius"][0])
+	assert.Equal(t, StrictFailureVR, res.DetailedErrors().Fields["width"][0])
+
+	res = validator(Map{"kind": "triangle"})
+	assert.False(t, res.Valid)
+	assert.Equal(t, `unknown discriminant value "triangle", expected one of [circle rect]`, res.Fields["kind"][0].Message)
+
+	res = validator(Map{"radius": 2})
+	assert.False(t, res.Valid)
+	assert.Contains(t, res.DetailedErrors().Fields["kind"][0].Message, "[circle rect]")
+
+	// Values must be strings, not merely print like one of the known values
+	res = Discriminate("n", map[string]Validator{"1": MustCompile(Map{})})(Map{"n": 1})
+	assert.False(t, res.Valid)
+}
+
+func TestCompileRecursive(t *testing.T) {
+	validator := CompileRecursive(func(self Validator) Validator {
+		return Strict(MustCompile(Map{
+			"text":    IsString,
+			"replies": Optional(IsSliceOf(Ref(self))),
+		}))
+	})
+
+	thread := Map{
+		"text": "root",
+		"replies": []interface{}{
+			Map{"text": "a", "replies": []interface{}{Map{"text": "a1"}}},
+			Map{"text": "b", "replies": []interface{}{}},
+		},
+	}
+	res := validator(thread)
+	assertResults(t, res)
+	assert.Contains(t, res.Fields, "replies.[0].replies.[0].text")
+
+	thread["replies"].([]interface{})[0].(Map)["replies"] = []interface{}{Map{"text": 1, "extra": true}}
+	res = validator(thread)
+	assert.False(t, res.Valid)
+	assert.False(t, res.Fields["replies.[0].replies.[0].text"][0].Valid)
+	assert.Equal(t, StrictFailureVR, res.Fields["replies.[0].replies.[0].extra"][0])
+}
+
+func TestIsEqualToDocument(t *testing.T) {
+	fixture := Map{
+		"id":    5,
+		"name":  "widget",
+		"tags":  []interface{}{"a", "b"},
+		"owner": Map{"name": "alice", "admin": false},
+		"note":  nil,
+	}
+	validator := IsEqualToDocument(fixture)
+
+	var decoded interface{}
+	require.NoError(t, json.Unmarshal(
+		[]byte(`{"id": 5, "name": "widget", "tags": ["a", "b"], "owner": {"name": "alice", "admin": false}, "note": null}`),
+		&decoded,
+	))
+	assertResults(t, validator(decoded))
+
+	res := validator(Map{
+		"id":    "5",
+		"name":  "gadget",
+		"tags":  []interface{}{"a"},
+		"owner": Map{"name": "alice", "extra": 1},
+		"note":  nil,
+	})
+	assert.False(t, res.Valid)
+	errs := res.DetailedErrors().Fields
+	assert.Len(t, errs, 5)
+	assert.Equal(t, `type mismatch: expected int 5, got string "5"`, errs["id"][0].Message)
+	assert.Contains(t, errs, "name")
+	assert.Equal(t, "expected slice of length 2, got length 1", errs["tags"][0].Message)
+	assert.Equal(t, KeyMissingVR, errs["owner.admin"][0])
+	assert.Equal(t, StrictFailureVR, errs["owner.extra"][0])
+
+	assert.False(t, validator(Map{"id": 6.0}).Valid)
+	assertResults(t, IsEqualToDocument(3)(3.0))
+}
+
 func TestStrictFunc(t *testing.T) {
 	m := Map{
 		"foo": "bar",
@@ -250,6 +585,132 @@ func TestStrictFunc(t *testing.T) {
 	assert.False(t, res.Valid)
 }
 
+func TestStrictSiblingPrefix(t *testing.T) {
+	// Testing foobar must not count as testing its sibling foo
+	res := Strict(MustCompile(Map{"foobar": 1}))(Map{"foo": 2, "foobar": 1})
+	assert.Equal(t, []ValueResult{StrictFailureVR}, res.DetailedErrors().Fields["foo"])
+
+	res = Strict(MustCompile(Map{"foo-x": 1, "foo.bar": 1}))(Map{"foo": Map{"bar": 1}, "foo-x": 1})
+	assertResults(t, res)
+}
+
+func TestConcurrentValidation(t *testing.T) {
+	validator := Strict(Compose(
+		MustCompile(Map{"foo": IsString, "items.[*].id": IsNumeric}),
+		MustCompile(Map{"foo": "bar", "unique": IsUnique()}),
+	))
+
+	done := make(chan bool)
+	for i := 0; i < 8; i++ {
+		go func(i int) {
+			m := Map{"foo": "bar", "items": []interface{}{Map{"id": i}}, "unique": i}
+			assertValidator(t, validator, m)
+			assert.False(t, validator(Map{"foo": "baz", "items": []interface{}{}, "unique": -1 - i}).Valid)
+			done <- true
+		}(i)
+	}
+	for i := 0; i < 8; i++ {
+		<-done
+	}
+}
+
+func TestLimit(t *testing.T) {
+	m := Map{}
+	for i := 0; i < 10000; i++ {
+		m[fmt.Sprintf("key%05d", i)] = i
+	}
+
+	validator := Strict(MustCompile(Map{"key00000": 0, "missing": "value"}))
+
+	full := validator(m)
+	assert.Len(t, full.Errors(), 10000)
+
+	limited := Limit(validator, 10)(m)
+	assert.False(t, limited.Valid)
+	assert.Equal(t, 9990, limited.Truncated)
+	// Passing results are kept
+	assert.True(t, limited.Fields["key00000"][0].Valid)
+	// The 10 failures with the smallest paths are kept
+	assert.Len(t, limited.DetailedErrors().Fields, 10)
+	assert.Contains(t, limited.Fields, "key00001")
+	assert.NotContains(t, limited.Fields, "missing")
+
+	errs := limited.Errors()
+	assert.Len(t, errs, 11)
+	assert.Contains(t, errs[10].Error(), "9990 additional failures were omitted")
+
+	// Valid results are unaffected by limits
+	assert.True(t, Limit(validator, 1)(Map{"key00000": 0, "missing": "value"}).Valid)
+}
+
+func TestStrictAt(t *testing.T) {
+	m := Map{
+		"lax": "anything",
+		"metadata": Map{
+			"name":  "foo",
+			"extra": "bar",
+			"labels": Map{
+				"a": "b",
+			},
+		},
+	}
+
+	laxValidator := MustCompile(Map{"metadata.name": "foo"})
+
+	res := StrictAt(MustParsePath("metadata"), laxValidator)(m)
+	assert.False(t, res.Valid)
+	errs := res.DetailedErrors().Fields
+	assert.Len(t, errs, 3)
+	assert.Equal(t, []ValueResult{StrictFailureVR}, errs["metadata.extra"])
+	assert.Equal(t, []ValueResult{StrictFailureVR}, errs["metadata.labels"])
+	assert.Equal(t, []ValueResult{StrictFailureVR}, errs["metadata.labels.a"])
+	assert.NotContains(t, errs, "lax")
+
+	res = StrictAt(MustParsePath("metadata.labels"), laxValidator)(m)
+	assert.False(t, res.Valid)
+	assert.Len(t, res.DetailedErrors().Fields, 1)
+	assert.Contains(t, res.DetailedErrors().Fields, "metadata.labels.a")
+
+	// A missing subtree has nothing unexpected in it
+	assertValidator(t, StrictAt(MustParsePath("nothere"), laxValidator), m)
+
+	// Similarly named keys outside the path are not affected
+	assertValidator(t, StrictAt(MustParsePath("meta"), laxValidator), Map{"metadata": Map{"name": "foo", "x": 1}})
+}
+
+func TestStrictWithSuggestions(t *testing.T) {
+	m := Map{
+		"nmae": "foo",
+		"nest": Map{
+			"cuont":     1,
+			"unrelated": true,
+		},
+		"zzzzzzz": 1,
+	}
+
+	validator := StrictWithSuggestions(MustCompile(Map{
+		"name":       IsString,
+		"nest.count": IsNumeric,
+	}))
+
+	res := validator(m)
+	assert.False(t, res.Valid)
+
+	errs := res.DetailedErrors().Fields
+	assert.Equal(t, KeyMissingVR, errs["name"][0])
+	assert.Contains(t, errs["nmae"][0].Message, "did you mean 'name'?")
+	assert.Contains(t, errs["nest.cuont"][0].Message, "did you mean 'count'?")
+	assert.Equal(t, StrictFailureVR, errs["nest.unrelated"][0])
+	assert.Equal(t, StrictFailureVR, errs["zzzzzzz"][0])
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein("name", "name"))
+	assert.Equal(t, 2, levenshtein("nmae", "name"))
+	assert.Equal(t, 3, levenshtein("kitten", "sitting"))
+	assert.Equal(t, 4, levenshtein("", "name"))
+}
+
 func TestOptional(t *testing.T) {
 	m := Map{
 		"foo": "bar",
@@ -262,6 +723,46 @@ func TestOptional(t *testing.T) {
 	assertValidator(t, validator, m)
 }
 
+func TestOptionalWithDefault(t *testing.T) {
+	validator := MustCompile(Map{
+		"retries": OptionalWithDefault(IsBetween(1, 10), 3),
+	})
+
+	// Absent keys are checked using the default
+	res := validator(Map{})
+	assertResults(t, res)
+	assert.Len(t, res.Fields["retries"], 1)
+
+	assertValidator(t, validator, Map{"retries": 5})
+	assert.False(t, validator(Map{"retries": 20}).Valid)
+
+	// A default violating the IsDef fails when the key is absent
+	res = MustCompile(Map{"retries": OptionalWithDefault(IsBetween(1, 10), 0)})(Map{})
+	assert.False(t, res.Valid)
+	assert.Contains(t, res.Fields["retries"][0].Message, "default value 0: ")
+	assertValidator(t, MustCompile(Map{"retries": OptionalWithDefault(IsBetween(1, 10), 0)}), Map{"retries": 2})
+}
+
+func TestNot(t *testing.T) {
+	m := Map{
+		"foo": "bar",
+	}
+
+	assertValidator(t, MustCompile(Map{"foo": Not(IsEqual("baz"))}), m)
+	assertValidator(t, MustCompile(Map{"foo": Not(IsStringMatchingStr("^z"))}), m)
+
+	res := MustCompile(Map{"foo": Not(IsEqual("bar"))})(m)
+	assert.False(t, res.Valid)
+	assert.Contains(t, res.Fields["foo"][0].Message, "expected NOT equals")
+
+	// Optional-ness is preserved, a missing key still passes
+	assertValidator(t, MustCompile(Map{"missing": Not(Optional(IsEqual("bar")))}), m)
+	// But a present value is still inverted
+	assert.False(t, MustCompile(Map{"foo": Not(Optional(IsEqual("bar")))})(m).Valid)
+	// Without Optional the key is still required
+	assert.False(t, MustCompile(Map{"missing": Not(IsEqual("bar"))})(m).Valid)
+}
+
 func TestExistence(t *testing.T) {
 	m := Map{
 		"exists": "foo",
@@ -275,6 +776,24 @@ func TestExistence(t *testing.T) {
 	assertValidator(t, validator, m)
 }
 
+func TestIsAbsent(t *testing.T) {
+	validator := Strict(MustCompile(Map{
+		"name":             IsString,
+		"legacy":           IsAbsent,
+		"items.[*].id":     IsNumeric,
+		"items.[*].old_id": IsAbsent,
+	}))
+
+	assertValidator(t, validator, Map{"name": "x", "items": []interface{}{Map{"id": 1}, Map{"id": 2}}})
+
+	res := validator(Map{"name": "x", "legacy": nil, "items": []interface{}{Map{"id": 1}, Map{"id": 2, "old_id": 3}}})
+	assert.False(t, res.Valid)
+	errs := res.DetailedErrors().Fields
+	assert.Len(t, errs, 2)
+	assert.Contains(t, errs["legacy"][0].Message, "should not exist")
+	assert.Contains(t, errs["items.[1].old_id"][0].Message, "present with value 3")
+}
+
 func TestComplex(t *testing.T) {
 	m := Map{
 		"foo": "bar",
@@ -465,3 +984,805 @@ func TestInvalidPathIsdef(t *testing.T) {
 
 	assert.Equal(t, InvalidPathString(badPath), err)
 }
+
+type compileStructBase struct {
+	ID string `json:"id"`
+}
+
+type compileStructItem struct {
+	Name  string  `json:"name"`
+	Price float64 `json:"price"`
+}
+
+type compileStructOrder struct {
+	compileStructBase
+	Customer string              `json:"customer"`
+	Paid     bool                `json:"paid,omitempty"`
+	Note     string              `json:"note" lookslike:"optional"`
+	Items    []compileStructItem `json:"items"`
+	Tags     map[string]string   `json:"tags"`
+	Shipped  *time.Time          `json:"shipped"`
+	Internal string              `json:"-"`
+	NoTag    int
+	private  string
+}
+
+func TestCompileStruct(t *testing.T) {
+	validator, err := CompileStruct(&compileStructOrder{})
+	require.NoError(t, err)
+
+	valid := Map{
+		"id":       "o-1",
+		"customer": "alice",
+		"paid":     true,
+		"items":    []interface{}{Map{"name": "pen", "price": 1.5}},
+		"tags":     map[string]interface{}{"rush": "yes"},
+		"shipped":  "2020-01-02T03:04:05Z",
+		"NoTag":    3,
+	}
+	assertValidator(t, validator, valid)
+
+	valid["shipped"] = nil
+	assertValidator(t, validator, valid)
+
+	res := validator(Map{
+		"id":       1,
+		"customer": "alice",
+		"paid":     "yes",
+		"items":    []interface{}{Map{"name": "pen", "price": "cheap"}},
+		"tags":     map[string]interface{}{"rush": 1},
+		"shipped":  "yesterday",
+	})
+	assert.False(t, res.Valid)
+	for _, p := range []string{"id", "paid", "items.[0].price", "tags.rush", "shipped", "NoTag"} {
+		assert.Contains(t, res.DetailedErrors().Fields, p)
+	}
+	assert.NotContains(t, res.DetailedErrors().Fields, "note")
+	assert.NotContains(t, res.Fields, "Internal")
+	assert.NotContains(t, res.Fields, "private")
+}
+
+type testTreeNode struct {
+	Name     string         `json:"name"`
+	Children []testTreeNode `json:"children"`
+	Parent   *testTreeNode  `json:"parent"`
+}
+
+func TestCompileStructRecursive(t *testing.T) {
+	validator, err := CompileStruct(testTreeNode{})
+	require.NoError(t, err)
+
+	assertResults(t, validator(Map{
+		"name":   "root",
+		"parent": nil,
+		"children": []interface{}{
+			Map{"name": "a", "parent": nil, "children": []interface{}{}},
+			Map{"name": "b", "parent": Map{"name": "root", "parent": nil, "children": []interface{}{}}, "children": []interface{}{}},
+		},
+	}))
+
+	res := validator(Map{
+		"name":   "root",
+		"parent": nil,
+		"children": []interface{}{
+			Map{"name": "a", "parent": nil, "children": []interface{}{Map{"name": 1, "parent": nil, "children": nil}}},
+		},
+	})
+	assert.False(t, res.Valid)
+	assert.Contains(t, res.ErrorMessages(), "children.[0].children.[0].name")
+}
+
+func TestCompileStructErrors(t *testing.T) {
+	_, err := CompileStruct("not a struct")
+	assert.Error(t, err)
+
+	_, err = CompileStruct(nil)
+	assert.Error(t, err)
+
+	_, err = CompileStruct(struct{ C chan int }{})
+	assert.Error(t, err)
+
+	_, err = CompileStruct(struct{ M map[int]string }{})
+	assert.Error(t, err)
+}
+
+func TestCompileJSONSchema(t *testing.T) {
+	validator, err := CompileJSONSchema([]byte(`{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"title": "order",
+		"type": "object",
+		"required": ["id", "status", "items"],
+		"properties": {
+			"id": {"type": "integer", "minimum": 1},
+			"status": {"enum": ["open", "closed"]},
+			"note": {"type": ["string", "null"]},
+			"items": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"required": ["qty"],
+					"properties": {"qty": {"type": "number", "minimum": 0, "maximum": 10}}
+				}
+			}
+		}
+	}`))
+	require.NoError(t, err)
+
+	assertValidator(t, validator, Map{
+		"id":     3,
+		"status": "open",
+		"note":   nil,
+		"items":  []interface{}{Map{"qty": 2.5}},
+	})
+
+	res := validator(Map{
+		"id":     0.5,
+		"status": "lost",
+		"note":   1,
+		"items":  []interface{}{Map{"qty": 11}, Map{}},
+	})
+	assert.False(t, res.Valid)
+	errs := res.DetailedErrors().Fields
+	for _, p := range []string{"id", "status", "note", "items.[0].qty", "items.[1].qty"} {
+		assert.Contains(t, errs, p)
+	}
+
+	res = validator(Map{"status": "open", "items": []interface{}{}})
+	assert.Equal(t, KeyMissingVR, res.DetailedErrors().Fields["id"][0])
+
+	res = validator("not an object")
+	assert.False(t, res.Valid)
+}
+
+func TestCompileJSONSchemaErrors(t *testing.T) {
+	_, err := CompileJSONSchema([]byte(`{"properties": {"name": {"type": "string", "pattern": "^a"}}}`))
+	assert.Equal(t, UnsupportedJSONSchemaKeyword{"pattern", "#/properties/name"}, err)
+
+	_, err = CompileJSONSchema([]byte(`{"items": [{"type": "string"}]}`))
+	assert.Equal(t, UnsupportedJSONSchemaKeyword{"items (list form)", "#"}, err)
+
+	_, err = CompileJSONSchema([]byte(`{"type": "widget"}`))
+	assert.Error(t, err)
+
+	_, err = CompileJSONSchema([]byte(`{`))
+	assert.Error(t, err)
+}
+
+func TestCompileNativeTypes(t *testing.T) {
+	schema := map[string]interface{}{
+		"foo": "bar",
+		"nested": map[string]interface{}{
+			"list": []interface{}{1, IsString},
+		},
+	}
+	validator, err := Compile(schema)
+	require.NoError(t, err)
+	assertValidator(t, validator, Map{"foo": "bar", "nested": Map{"list": []interface{}{1, "x"}}})
+	assert.False(t, validator(Map{"foo": "baz", "nested": Map{"list": []interface{}{1, "x"}}}).Valid)
+
+	validator, err = Compile([]interface{}{"a", IsNumeric})
+	require.NoError(t, err)
+	assertResults(t, validator([]interface{}{"a", 2}))
+	assert.False(t, validator([]interface{}{"a", 2, 3}).Valid)
+
+	_, err = Compile(42)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "(int)")
+	assert.Contains(t, err.Error(), "map[string]interface{}")
+}
+
+func TestCompileCached(t *testing.T) {
+	isFoo := IsEqual("foo")
+	build := func() Map {
+		return Map{"a": 1, "b": isFoo, "c": Slice{IsString, Optional(isFoo), Map{"d": true}}}
+	}
+	doc := Map{"a": 1, "b": "foo", "c": []interface{}{"x", "foo", Map{"d": true}}}
+	cacheSize := func() int {
+		compileCache.Lock()
+		defer compileCache.Unlock()
+		return len(compileCache.validators)
+	}
+
+	before := cacheSize()
+	first, err := CompileCached(build())
+	require.NoError(t, err)
+	second, err := CompileCached(build())
+	require.NoError(t, err)
+	assert.Equal(t, before+1, cacheSize())
+	assert.Equal(t, reflect.ValueOf(first).Pointer(), reflect.ValueOf(second).Pointer())
+	assertValidator(t, second, doc)
+
+	// Different values, and different IsDef instances, produce different validators
+	_, err = CompileCached(Map{"a": 2, "b": isFoo, "c": Slice{IsString, Optional(isFoo), Map{"d": true}}})
+	require.NoError(t, err)
+	assert.Equal(t, before+2, cacheSize())
+
+	v, err := CompileCached(Map{"a": 1, "b": IsEqual("bar"), "c": Slice{IsString, Optional(isFoo), Map{"d": true}}})
+	require.NoError(t, err)
+	assert.Equal(t, before+3, cacheSize())
+	assert.False(t, v(doc).Valid)
+
+	// Values that can't be keyed are compiled without being cached
+	v, err = CompileCached(Map{"a": &doc})
+	require.NoError(t, err)
+	assert.True(t, v(Map{"a": &doc}).Valid)
+	assert.Equal(t, before+3, cacheSize())
+
+	_, err = CompileCached(42)
+	assert.Error(t, err)
+	assert.Equal(t, before+3, cacheSize())
+}
+
+func TestCompileCachedBounded(t *testing.T) {
+	for i := 0; i < maxCachedValidators+10; i++ {
+		v, err := CompileCached(Map{"n": i})
+		require.NoError(t, err)
+		assertValidator(t, v, Map{"n": i})
+	}
+
+	compileCache.Lock()
+	defer compileCache.Unlock()
+	assert.Len(t, compileCache.validators, maxCachedValidators)
+	assert.Len(t, compileCache.keys, maxCachedValidators)
+}
+
+func TestCompileCachedConcurrent(t *testing.T) {
+	done := make(chan bool)
+	for i := 0; i < 8; i++ {
+		go func() {
+			v, err := CompileCached(Map{"foo": IsString, "n": 1})
+			assert.NoError(t, err)
+			assert.True(t, v(Map{"foo": "bar", "n": 1}).Valid)
+			done <- true
+		}()
+	}
+	for i := 0; i < 8; i++ {
+		<-done
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	validator := MustCompile(Map{"id": IsEqual(json.Number("12345678901234567890")), "name": "foo"})
+	body := `{"id": 12345678901234567890, "name": "foo"}`
+
+	res, err := ValidateJSON(strings.NewReader(body), validator, UseNumber)
+	require.NoError(t, err)
+	assertResults(t, res)
+
+	// Without UseNumber the id is decoded as a float64
+	res, err = ValidateJSON(strings.NewReader(body), MustCompile(Map{"id": IsType(float64(0))}))
+	require.NoError(t, err)
+	assertResults(t, res)
+
+	res, err = ValidateJSON(strings.NewReader(`{"name": "bar"}`), validator)
+	require.NoError(t, err)
+	assert.False(t, res.Valid)
+
+	_, err = ValidateJSON(strings.NewReader(`{"name": `), validator)
+	assert.Error(t, err)
+
+	_, err = ValidateJSON(strings.NewReader(`{"name": "foo"} {}`), validator)
+	assert.Error(t, err)
+}
+
+func TestValidateJSONArrayStream(t *testing.T) {
+	validator := MustCompile(Map{"id": IsNumeric})
+	input := `[{"id": 1}, {"id": "two"}, {"id": 3}]`
+
+	var valid []bool
+	err := ValidateJSONArrayStream(strings.NewReader(input), validator, func(idx int, r *Results) bool {
+		assert.Equal(t, len(valid), idx)
+		valid = append(valid, r.Valid)
+		return true
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false, true}, valid)
+
+	// Returning false stops validation, even before malformed data later in the stream
+	valid = nil
+	err = ValidateJSONArrayStream(strings.NewReader(`[{"id": 1}, {"id": 2}, {"id": `), validator, func(idx int, r *Results) bool {
+		valid = append(valid, r.Valid)
+		return idx < 1
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, true}, valid)
+
+	err = ValidateJSONArrayStream(strings.NewReader(`[]`), validator, func(idx int, r *Results) bool {
+		t.Error("no elements should be validated")
+		return true
+	})
+	require.NoError(t, err)
+
+	noop := func(idx int, r *Results) bool { return true }
+	assert.Error(t, ValidateJSONArrayStream(strings.NewReader(`{"id": 1}`), validator, noop))
+	assert.Error(t, ValidateJSONArrayStream(strings.NewReader(`[{"id": 1}, {"id"`), validator, noop))
+	assert.Error(t, ValidateJSONArrayStream(strings.NewReader(`[{"id": 1}] []`), validator, noop))
+	assert.Error(t, ValidateJSONArrayStream(strings.NewReader(``), validator, noop))
+}
+
+func TestValidateNDJSON(t *testing.T) {
+	validator := MustCompile(Map{"level": IsOneOf("info", "error")})
+	input := "{\"level\": \"info\"}\n\n{\"level\": \"debug\"}\r\n{\"level\": \n{\"level\": \"error\"} {}\n{\"level\": \"error\"}"
+
+	results, err := ValidateNDJSON(strings.NewReader(input), validator)
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+
+	assertResults(t, results[0])
+
+	require.False(t, results[1].Valid)
+	assert.True(t, strings.HasPrefix(results[1].Fields["level"][0].Message, "line 3: "))
+
+	for i, line := range []int{4, 5} {
+		res := results[i+2]
+		require.False(t, res.Valid)
+		msg := res.Fields[""][0].Message
+		assert.True(t, strings.HasPrefix(msg, fmt.Sprintf("line %d: invalid JSON: ", line)), msg)
+	}
+
+	assertResults(t, results[4])
+
+	results, err = ValidateNDJSON(strings.NewReader(""), validator)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestCompileSchema(t *testing.T) {
+	schema, err := CompileSchema(Map{
+		"name": IsString,
+		"tags": []interface{}{"a", IsString},
+		"nested": Map{
+			"count": Optional(IsIntGt(0)),
+			"empty": Map{},
+		},
+		"hosts.*.ip": IsString,
+	})
+	require.NoError(t, err)
+
+	var described []string
+	for _, e := range schema.Entries() {
+		described = append(described, fmt.Sprintf("%s %s %t", e.Path, e.Name, e.Optional))
+	}
+	assert.Equal(t, []string{
+		"hosts.*.ip is a string false",
+		"name is a string false",
+		"nested.count Optional greater than true",
+		"nested.empty equals false",
+		"tags.[0] equals false",
+		"tags.[1] is a string false",
+	}, described)
+
+	// The compiled schema validates just like the Validator Compile returns
+	assertResults(t, schema.Check(Map{"name": "foo", "tags": []interface{}{"a", "b"}, "nested": Map{"empty": Map{}}, "hosts": Map{}}))
+
+	schema, err = CompileSchema(IsString)
+	require.NoError(t, err)
+	require.Len(t, schema.Entries(), 1)
+	assert.Equal(t, "", schema.Entries()[0].Path.String())
+
+	_, err = CompileSchema(1)
+	assert.Error(t, err)
+}
+
+func TestIsDefNameInResults(t *testing.T) {
+	validator := MustCompile(Map{
+		"name":  IsStringContaining("o"),
+		"count": IsIntGt(5),
+		"tags":  IsSliceOf(IsString),
+		"exact": "value",
+	})
+	doc := Map{"name": "foo", "count": 1, "tags": []interface{}{"a"}, "exact": "value"}
+
+	// Without opting in, passing results aren't named, and compare equal to ValidVR
+	res := validator(doc)
+	assert.Equal(t, []ValueResult{ValidVR}, res.Fields["name"])
+	assert.Equal(t, []ValueResult{ValidVR}, res.Fields["tags.[0]"])
+
+	res = WithIsDefNames(validator)(doc)
+
+	names := map[string]string{}
+	res.EachResult(func(path Path, vr ValueResult) bool {
+		names[path.String()] = vr.IsDefName
+		return true
+	})
+
+	assert.Equal(t, "is string containing", names["name"])
+	assert.Equal(t, "equals", names["exact"])
+	// The innermost IsDef that validated a value is reported
+	assert.Equal(t, "is a string", names["tags.[0]"])
+	// Failures aren't named
+	assert.Equal(t, "", names["count"])
+	assert.False(t, res.Fields["count"][0].Valid)
+}
+
+func TestCoverage(t *testing.T) {
+	schema := MustCompile(Map{
+		"name":         IsString,
+		"nickname":     Optional(IsString),
+		"hosts.[*].ip": IsString,
+		"hosts.[*].os": Optional(IsString),
+		"nested":       Map{"a": 1, "b": 2},
+	})
+
+	report := Coverage(schema, Map{
+		"name":   "foo",
+		"hosts":  []interface{}{Map{"ip": "10.0.0.1"}, Map{"ip": "10.0.0.2"}},
+		"nested": Map{"b": 2},
+	})
+	assert.Equal(t, []string{"hosts.[*].ip", "name", "nested.b"}, report.Checked)
+	assert.Equal(t, []string{"hosts.[*].os", "nested.a", "nickname"}, report.Absent)
+	assert.False(t, report.Complete())
+
+	report = Coverage(schema, Map{
+		"name":     "foo",
+		"nickname": "f",
+		"hosts":    []interface{}{Map{"ip": "10.0.0.1", "os": "linux"}},
+		"nested":   Map{"a": 1, "b": 2},
+	})
+	assert.Empty(t, report.Absent)
+	assert.True(t, report.Complete())
+
+	// Paths declared by composed and wrapped schemas are all covered
+	composed := Limit(Strict(Compose(schema, MustCompile(Map{"extra": IsString}))), 1)
+	report = Coverage(composed, Map{"name": 1})
+	assert.Equal(t, []string{"name"}, report.Checked)
+	assert.Equal(t, []string{"extra", "hosts.[*].ip", "hosts.[*].os", "nested.a", "nested.b", "nickname"}, report.Absent)
+}
+
+func TestWithCoercionWarnings(t *testing.T) {
+	schema := Map{
+		"float":   IsGT(1),
+		"int":     IsGT(1),
+		"numeq":   IsNumEqual(5),
+		"jsonnum": IsEqual(5),
+		"exact":   IsEqual(5),
+	}
+	doc := Map{
+		"float":   2.0,
+		"int":     2,
+		"numeq":   5.0,
+		"jsonnum": json.Number("5"),
+		"exact":   5,
+	}
+
+	// Without opting in, coercion isn't reported
+	res := MustCompile(schema)(doc)
+	assertResults(t, res)
+	assert.Empty(t, res.Warnings())
+	// Nor does it make the passing results any different from ones that needed no coercion
+	for _, key := range []string{"int", "numeq", "jsonnum"} {
+		assert.Equal(t, []ValueResult{ValidVR}, res.Fields[key], key)
+		assert.True(t, res.Fields[key][0] == ValidVR, key)
+	}
+
+	res = WithCoercionWarnings(MustCompile(schema))(doc)
+	assertResults(t, res)
+	warnings := res.Warnings()
+	assert.Len(t, warnings, 3)
+	assert.Equal(t, []string{"passed only after coercing int(2) to float64"}, warnings["int"])
+	assert.Equal(t, []string{"passed only after coercing float64(5) to int"}, warnings["numeq"])
+	assert.Equal(t, []string{"passed only after coercing json.Number(5) to int"}, warnings["jsonnum"])
+
+	// Failures are unaffected
+	res = WithCoercionWarnings(MustCompile(schema))(Map{"float": 0, "int": 2, "numeq": 5, "jsonnum": 5, "exact": 5})
+	assert.False(t, res.Valid)
+	assert.Equal(t, []string{"passed only after coercing int(2) to float64"}, res.Warnings()["int"])
+}
+
+func TestOptionalTree(t *testing.T) {
+	validator := Strict(MustCompile(Map{
+		"name": IsString,
+		"address": OptionalTree(Map{
+			"street": IsString,
+			"city":   IsString,
+			"geo":    OptionalTree(Map{"lat": IsNumeric, "lon": IsNumeric}),
+			"note":   Optional(IsString),
+		}),
+	}))
+
+	assertResults(t, validator(Map{"name": "foo"}))
+	assertResults(t, validator(Map{"name": "foo", "address": Map{"street": "main", "city": "x"}}))
+	assertResults(t, validator(Map{
+		"name":    "foo",
+		"address": Map{"street": "main", "city": "x", "geo": Map{"lat": 1, "lon": 2}},
+	}))
+
+	res := validator(Map{"name": "foo", "address": Map{"street": "main"}})
+	assert.False(t, res.Valid)
+	assert.Equal(t, KeyMissingVR, res.Fields["address.city"][0])
+
+	res = validator(Map{"name": "foo", "address": Map{"street": "main", "city": "x", "geo": Map{"lat": 1}}})
+	assert.False(t, res.Valid)
+	assert.Equal(t, KeyMissingVR, res.Fields["address.geo.lon"][0])
+
+	res = validator(Map{"name": "foo", "address": Map{"street": "main", "city": "x", "extra": 1}})
+	assert.False(t, res.Valid)
+	assert.Equal(t, StrictFailureVR, res.Fields["address.extra"][0])
+
+	// An object whose children are all optional is still known to Strict
+	allOptional := Strict(MustCompile(Map{"meta": OptionalTree(Map{"note": Optional(IsString)})}))
+	assertResults(t, allOptional(Map{"meta": Map{}}))
+
+	res = validator(Map{"name": "foo", "address": nil})
+	assert.False(t, res.Valid)
+}
+
+func TestWithMessage(t *testing.T) {
+	validator := MustCompile(Map{
+		"total":    WithMessage(IsGT(0), "order total must be positive"),
+		"currency": WithMessage(IsString, "currency is required"),
+		"note":     Optional(WithMessage(IsString, "note must be text")),
+	})
+
+	res := validator(Map{"total": 10, "currency": "EUR"})
+	assertResults(t, res)
+	assert.Equal(t, "is valid", res.Fields["total"][0].Message)
+
+	res = validator(Map{"total": -1, "note": 1})
+	assert.False(t, res.Valid)
+	assert.Equal(t, map[string]string{
+		"total":    "order total must be positive",
+		"currency": "currency is required",
+		"note":     "note must be text",
+	}, res.ErrorMessages())
+}
+
+func TestAsWarning(t *testing.T) {
+	validator := MustCompile(Map{
+		"name":        IsString,
+		"description": AsWarning(IsNonEmptyString),
+		"tags":        AsWarning(WithMessage(IsLengthGTE(1), "should have tags")),
+	})
+
+	res := validator(Map{"name": "foo", "description": "bar", "tags": []string{"a"}})
+	assertResults(t, res)
+	assert.Empty(t, res.Warnings())
+
+	res = validator(Map{"name": "foo", "tags": []string{}})
+	assertResults(t, res)
+	assert.Equal(t, map[string][]string{
+		"description": {KeyMissingVR.Message},
+		"tags":        {"should have tags"},
+	}, res.Warnings())
+
+	// Warnings don't mask real failures
+	res = validator(Map{"name": 1, "description": ""})
+	assert.False(t, res.Valid)
+	assert.Len(t, res.ErrorMessages(), 1)
+	assert.Contains(t, res.ErrorMessages(), "name")
+	assert.Len(t, res.Warnings(), 2)
+}
+
+func TestValidateAt(t *testing.T) {
+	address := MustCompile(Map{"street": IsString, "city": IsString})
+	doc := Map{
+		"billing":  Map{"address": Map{"street": "main", "city": "x"}},
+		"shipping": []interface{}{Map{"street": "a", "city": "y"}, Map{"street": 1, "city": "z"}},
+	}
+
+	res := ValidateAt(MustParsePath("billing.address"), address, doc)
+	assertResults(t, res)
+	assert.Contains(t, res.Fields, "billing.address.street")
+
+	res = ValidateAt(MustParsePath("shipping.[*]"), address, doc)
+	assert.False(t, res.Valid)
+	assert.True(t, res.Fields["shipping.[0].street"][0].Valid)
+	assert.False(t, res.Fields["shipping.[1].street"][0].Valid)
+
+	res = ValidateAt(MustParsePath("billing.missing"), address, doc)
+	assert.False(t, res.Valid)
+	assert.Equal(t, map[string][]ValueResult{"billing.missing": {KeyMissingVR}}, res.Fields)
+
+	res = ValidateAt(MustParsePath("billing.address.street.deeper"), address, doc)
+	assert.Equal(t, []ValueResult{KeyMissingVR}, res.Fields["billing.address.street.deeper"])
+}
+
+func TestMergeMaps(t *testing.T) {
+	base := Map{
+		"id":   IsNumeric,
+		"meta": Map{"created": IsRFC3339, "tags": IsSliceOf(IsString)},
+		"host": map[string]interface{}{"name": IsString},
+		"user": IsString,
+	}
+	overlay := Map{
+		"meta":  Map{"tags": IsLengthGTE(1), "owner": IsString},
+		"host":  Map{"ip": IsIP},
+		"user":  Map{"name": IsString},
+		"extra": Map{"a": 1},
+	}
+
+	merged := MergeMaps(base, overlay)
+	schema, err := CompileSchema(merged)
+	require.NoError(t, err)
+
+	var described []string
+	for _, e := range schema.Entries() {
+		described = append(described, e.Path.String()+" "+e.Name)
+	}
+	assert.Equal(t, []string{
+		"extra.a equals",
+		"host.ip is an IP address",
+		"host.name is a string",
+		"id is numeric",
+		"meta.created is a time",
+		"meta.owner is a string",
+		"meta.tags has length >=",
+		"user.name is a string",
+	}, described)
+
+	// The inputs are untouched, and the result doesn't share maps with them
+	assert.Len(t, base["meta"], 2)
+	assert.Len(t, overlay["meta"], 2)
+	merged["meta"].(Map)["new"] = 1
+	merged["extra"].(Map)["new"] = 1
+	assert.Len(t, base["meta"], 2)
+	assert.Len(t, overlay["extra"], 1)
+
+	assert.Equal(t, Map{}, MergeMaps(nil, nil))
+}
+
+func TestRequired(t *testing.T) {
+	required := Required("id", "owner.name", "items.[*].sku")
+
+	assertResults(t, required(Map{
+		"id":    nil,
+		"owner": Map{"name": 1},
+		"items": []interface{}{Map{"sku": "a"}},
+	}))
+
+	res := required(Map{"owner": Map{}, "items": []interface{}{Map{"sku": "a"}, Map{}}})
+	assert.False(t, res.Valid)
+	assert.Equal(t, map[string]string{
+		"id":            KeyMissingVR.Message,
+		"owner.name":    KeyMissingVR.Message,
+		"items.[1].sku": KeyMissingVR.Message,
+	}, res.ErrorMessages())
+
+	// Composed with value checks, both presence and values are validated
+	validator := Compose(required, MustCompile(Map{"id": Optional(IsNumeric)}))
+	res = validator(Map{"id": "x", "owner": Map{"name": "a"}, "items": []interface{}{}})
+	assert.False(t, res.Valid)
+	assert.Len(t, res.ErrorMessages(), 1)
+	assert.Contains(t, res.ErrorMessages(), "id")
+
+	assert.Panics(t, func() { Required("a..b") })
+}
+
+type testOrder struct {
+	ID       int               `json:"id"`
+	Customer testCustomer      `json:"customer"`
+	Items    []testItem        `json:"items"`
+	Labels   map[string]string `json:"labels"`
+	Created  time.Time         `json:"created"`
+	internal string
+}
+
+type testCustomer struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+}
+
+type testItem struct {
+	SKU   string  `json:"sku"`
+	Price float64 `json:"price"`
+}
+
+func TestValidateStructs(t *testing.T) {
+	order := &testOrder{
+		ID:       1,
+		Customer: testCustomer{Name: "foo"},
+		Items:    []testItem{{"a", 1.5}, {"b", 2}},
+		Labels:   map[string]string{"env": "prod"},
+		Created:  time.Now(),
+		internal: "hidden",
+	}
+
+	validator := Strict(MustCompile(Map{
+		"id":              IsNumeric,
+		"customer":        Map{"name": IsString, "email": Optional(IsEmail)},
+		"items.[*].sku":   IsString,
+		"items.[*].price": IsGT(0),
+		"labels.*":        IsString,
+		"created":         IsType(time.Time{}),
+	}))
+	// The email field is present in the struct, even if it is empty
+	res := validator(order)
+	assert.False(t, res.Valid)
+	assert.Len(t, res.ErrorMessages(), 1)
+	assert.False(t, res.Fields["customer.email"][0].Valid)
+
+	order.Customer.Email = "foo@example.net"
+	assertResults(t, validator(order))
+
+	// Fields the schema doesn't mention are unexpected
+	res = Strict(MustCompile(Map{"id": 1, "items": IsSliceOf(IsAny())}))(order)
+	assert.False(t, res.Valid)
+	assert.Equal(t, StrictFailureVR, res.Fields["customer.name"][0])
+	assert.NotContains(t, res.Fields, "internal")
+	assert.NotContains(t, res.Fields, "created.wall")
+
+	// Structs checked as a whole are values, whose fields aren't unexpected
+	res = Strict(MustCompile(Map{
+		"id":       1,
+		"customer": IsEqual(testCustomer{Name: "foo", Email: "foo@example.net"}),
+		"items":    IsSliceOf(IsMapWithKeys("sku", "price")),
+		"labels":   IsMapOf(IsString),
+		"created":  IsNonNil,
+	}))(order)
+	assertResults(t, res)
+}
+
+type testProfile struct {
+	Name     *string       `json:"name"`
+	Nickname *string       `json:"nickname"`
+	Age      *int          `json:"age"`
+	Address  *testCustomer `json:"address"`
+	Tags     []*string     `json:"tags"`
+	Extra    *Map          `json:"extra"`
+}
+
+func TestValidatePointers(t *testing.T) {
+	name, tag, age := "foo", "a", 30
+	profile := &testProfile{
+		Name:  &name,
+		Age:   &age,
+		Tags:  []*string{&tag, nil},
+		Extra: &Map{"k": "v"},
+	}
+
+	validator := Strict(MustCompile(Map{
+		"name":     "foo",
+		"nickname": Optional(IsString),
+		"age":      IsGT(18),
+		"address":  IsNil,
+		"tags.[0]": IsStringMatching(regexp.MustCompile("^a$")),
+		"tags.[1]": IsNil,
+		"extra.k":  IsString,
+	}))
+	assertResults(t, validator(profile))
+
+	// Non-nil pointers are followed into the structs they point to
+	profile.Address = &testCustomer{Name: "bar"}
+	res := MustCompile(Map{"address.name": IsString, "address.email": "bar@example.net"})(profile)
+	assert.False(t, res.Valid)
+	assert.Len(t, res.ErrorMessages(), 1)
+	assert.Contains(t, res.ErrorMessages(), "address.email")
+
+	// A nil pointer is only absent for Optional IsDefs
+	res = MustCompile(Map{"nickname": IsString})(profile)
+	assert.False(t, res.Valid)
+	assertResults(t, MustCompile(Map{"nickname": Optional(IsNil)})(profile))
+	assertResults(t, MustCompile(Map{"nickname": IsJSONNull})(profile))
+
+	// The root value may be a pointer too
+	assertResults(t, MustCompile(IsString)(&name))
+	assertResults(t, MustCompile(Map{"k": "v"})(profile.Extra))
+}
+
+// benchmarkDocument builds a document with 5000 leaf fields spread across nested maps, along with a schema
+// checking all of them.
+func benchmarkDocument() (Map, Map) {
+	doc := Map{}
+	schema := Map{}
+	for i := 0; i < 50; i++ {
+		group := Map{}
+		for j := 0; j < 100; j++ {
+			group[fmt.Sprintf("field%d", j)] = j
+			schema[fmt.Sprintf("group%d.field%d", i, j)] = j
+		}
+		doc[fmt.Sprintf("group%d", i)] = group
+	}
+	return doc, schema
+}
+
+func BenchmarkStrict(b *testing.B) {
+	doc, schema := benchmarkDocument()
+	validator := Strict(MustCompile(schema))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !validator(doc).Valid {
+			b.Fatal("expected document to be valid")
+		}
+	}
+}
@@ -19,5 +19,53 @@
 Package lookslike is used to validate JSON-like nested map data-structure against a set of expectations. Its key features are allowing custom, function defined validators  for values, and allowing the composition of multiple validation specs.
 
 See the example below for more details. Most key functions include detailed examples of their use within this documentation.
+
+# Structs
+
+Structs, and pointers to structs, are traversed like maps, so typed values such as decoded protobuf messages can be
+validated without converting them to a Map first. This applies to path lookups, Strict, wildcards, and IsDefs
+expecting maps, such as IsMapOf. The key of a field is the name in its json tag if it has one, so it matches the
+key the field is encoded under by encoding/json. Otherwise fields of protobuf generated structs use the field name
+from their protobuf tag, which is the name in the .proto file, such as user_id, and other fields use their go name.
+Unexported fields and fields tagged json:"-" are skipped, and the fields of embedded structs are promoted. A oneof
+field of a protobuf message is not a key itself; instead its member that is set, if any, is present under the
+member's name, as it is in the JSON encoding of the message. Structs that encode themselves, by implementing
+json.Marshaler or encoding.TextMarshaler as time.Time does, are treated as values rather than traversed. With Strict,
+a struct checked as a whole, such as with IsEqual, doesn't have its fields reported as unexpected.
+
+# Pointers
+
+Non-nil pointers, such as the *string fields structs often use for optional values, are followed wherever values are
+looked up or traversed, so IsDefs see the value pointed to; IsString passes for a non-nil *string, and IsEqual("a")
+compares against the string it points to. A nil pointer found at a path is checked as nil, so IsNil passes for it,
+while for Optional IsDefs it counts as absent, so Optional(IsString) passes for a nil *string field. Likewise Strict
+doesn't report nil pointers that aren't checked as unexpected. IsDefs called directly with a value through Check
+receive it as it is.
+
+# Untrusted input
+
+Validators are meant to be run against untrusted documents, such as decoded request bodies, and report unexpected
+values as failures rather than panicking, whatever their types. An IsDef whose Checker panics, as a custom one
+might for a type it doesn't expect, fails at its path with a message including the panic. FuzzValidate checks this
+for arbitrary JSON; run it with go test -fuzz FuzzValidate.
+
+Decoded JSON can't contain cycles, but documents built in code can, such as a map holding itself or a struct
+pointing to itself. Strict reports a map, slice or pointer within its own descendants as a failure at the path
+where the cycle closes, such as "cycle detected at path parent.child", and checks the rest of the document rather
+than traversing the cycle forever. IsDefs checking such a value itself, rather than values
+beneath it, may still not terminate, since failure messages quote the values they fail on.
+
+# Concurrency
+
+Compiled Validators hold no mutable state of their own, and each invocation returns a new Results, so a single
+Validator may be called from multiple goroutines at once, for instance from HTTP request handlers. The same goes for
+the combinators wrapping Validators, such as Strict and Compose, and for the IsDefs provided by this package,
+with two exceptions. IsUnique, and the trackers returned by ScopedIsUnique, intentionally remember every value they
+see across invocations. They may be used concurrently without data races, but the outcome then depends on the order
+in which goroutines run. Capture writes to the pointer it is given, so Validators using it must not be run
+concurrently. Custom IsDefs must themselves be safe for concurrent use for a Validator using them to be.
+
+RegisterEqual may be called concurrently with validation, though it's typically best called during initialization.
+Values being validated must not be modified while a Validator is running against them.
 */
 package lookslike
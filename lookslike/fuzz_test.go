@@ -0,0 +1,124 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package lookslike
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// fuzzValidators returns Validators covering most IsDefs and combinators, all of which must fail gracefully
+// rather than panic for any decoded JSON document.
+func fuzzValidators() []Validator {
+	schema := Map{
+		"id":        IsInteger,
+		"big":       IsInt64,
+		"name":      IsStringMatching(regexp.MustCompile("^[a-z]+$")),
+		"email":     Optional(IsEmail),
+		"url":       IsURLWithScheme("https"),
+		"ip":        IsIP,
+		"cidr":      IsCIDR,
+		"uuid":      IsUUIDVersion(4),
+		"hex":       IsHex,
+		"b64":       IsBase64,
+		"created":   IsRFC3339,
+		"took":      IsDurationGTE(time.Second),
+		"ratio":     IsRatio,
+		"count":     IsNumericStringEqual(3),
+		"level":     IsEnum([]string{"debug", "info"}),
+		"status":    IsOneOf("a", 1.0, nil),
+		"payload":   IsJSONStringMatching(MustCompile(Map{"k": IsString})),
+		"tags":      IsSliceOf(IsNonEmptyString),
+		"sorted":    IsSorted,
+		"unique":    IsSliceUnique,
+		"subset":    IsSubsetOf(Slice{"a", "b"}),
+		"labels":    IsMapOf(IsLengthLTE(3)),
+		"keys":      IsMapWithKeysMatching(IsStringMatchingStr("^x")),
+		"captures":  IsStringCaptures(regexp.MustCompile(`^(\d+)-(\w+)?$`), map[int]IsDef{1: IsNumericString, 2: Optional(IsString)}),
+		"either":    AnyOf(IsBool, IsPositive),
+		"not":       Not(IsNil),
+		"null":      IsJSONNull,
+		"dflt":      OptionalWithDefault(IsString, "x"),
+		"items.*.a": IsEqual(1.0),
+		"deep.[*]":  IsLength(2),
+		"tree":      OptionalTree(Map{"a.b": IsType("")}),
+	}
+
+	lax := MustCompile(schema)
+	return []Validator{
+		lax,
+		Strict(lax),
+		StrictWithSuggestions(lax),
+		WithCoercionWarnings(lax),
+		Limit(lax, 2),
+		MustCompile(Slice{IsAny(), IsString}),
+		MustCompile(IsEqualIgnoring(Map{"a": 1.0, "b": Slice{1.0}}, "b")),
+		Discriminate("type", map[string]Validator{"a": lax, "b": Strict(MustCompile(Map{"b": IsNumeric}))}),
+		Required("id", "items.[0].a"),
+	}
+}
+
+func FuzzValidate(f *testing.F) {
+	for _, seed := range []string{
+		`{}`,
+		`null`,
+		`[1, "a", null]`,
+		`{"id": 3.5, "name": {"x": 1}, "tags": ["", null, []], "labels": {"a": [1, 2, 3, 4]}}`,
+		`{"sorted": [1, "a", null, {}], "unique": [[1], [1]], "payload": "{\"k\": [", "captures": "12-"}`,
+		`{"items": {"x": {"a": 1}, "y": null}, "deep": [[1, 2], "ab", 3], "tree": {"a": {"b": 1}}}`,
+		`{"big": 1e300, "count": "NaN", "took": "-1h", "created": 5, "keys": {"": 1}, "type": "b"}`,
+		`{"a.b": 1, "[0]": 2, "*": 3, "": {"": []}}`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	validators := fuzzValidators()
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var docs []interface{}
+		var doc interface{}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return
+		}
+		docs = append(docs, doc)
+
+		// Decoding with UseNumber yields json.Numbers rather than float64s
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		var numDoc interface{}
+		if err := dec.Decode(&numDoc); err == nil {
+			docs = append(docs, numDoc)
+		}
+
+		for _, d := range docs {
+			for _, v := range validators {
+				res := v(d)
+				if res == nil {
+					t.Fatalf("validator returned nil results for %s", data)
+				}
+				_ = res.Errors()
+				_, _ = json.Marshal(res)
+			}
+		}
+	})
+}
@@ -0,0 +1,78 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package gomegalike adapts lookslike for use with the Gomega matcher library, so that assertions can be
+// written as Expect(payload).To(LooksLike(lookslike.Map{...})). It doesn't import Gomega itself, since the
+// Matcher satisfies Gomega's types.GomegaMatcher interface structurally.
+package gomegalike
+
+import (
+	"fmt"
+
+	"github.com/davecgh/go-spew/spew"
+
+	"github.com/elastic/lookslike/lookslike"
+)
+
+// Matcher is a Gomega matcher checking values against a lookslike schema.
+type Matcher struct {
+	schema    interface{}
+	validator lookslike.Validator
+	err       error
+}
+
+// LooksLike creates a Matcher for the given schema, which may be anything accepted by lookslike.Compile,
+// or an already compiled lookslike.Validator. Schemas that fail to compile are reported as an error by Match.
+func LooksLike(schema interface{}) *Matcher {
+	if validator, ok := schema.(lookslike.Validator); ok {
+		return &Matcher{schema: schema, validator: validator}
+	}
+
+	validator, err := lookslike.Compile(schema)
+	return &Matcher{schema: schema, validator: validator, err: err}
+}
+
+// Match returns whether the actual value is valid according to the schema.
+func (m *Matcher) Match(actual interface{}) (success bool, err error) {
+	if m.err != nil {
+		return false, m.compileError()
+	}
+	return m.validator(actual).Valid, nil
+}
+
+// FailureMessage describes why the actual value is invalid, listing each failing path, or why the schema
+// couldn't be compiled.
+func (m *Matcher) FailureMessage(actual interface{}) (message string) {
+	if m.err != nil {
+		return m.compileError().Error()
+	}
+	results := m.validator(actual).DetailedErrors()
+	return fmt.Sprintf(
+		"Expected\n%s\nto look like the schema, but found %d errors:\n%s",
+		spew.Sdump(actual), len(results.Errors()), results.Tree(),
+	)
+}
+
+// NegatedFailureMessage describes the actual value that unexpectedly matched the schema.
+func (m *Matcher) NegatedFailureMessage(actual interface{}) (message string) {
+	return fmt.Sprintf("Expected\n%s\nnot to look like the schema\n%s", spew.Sdump(actual), spew.Sdump(m.schema))
+}
+
+// compileError describes the error compiling the schema.
+func (m *Matcher) compileError() error {
+	return fmt.Errorf("LooksLike could not compile schema: %v", m.err)
+}
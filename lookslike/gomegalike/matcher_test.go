@@ -0,0 +1,71 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package gomegalike
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/lookslike/lookslike"
+)
+
+// gomegaMatcher mirrors Gomega's types.GomegaMatcher, which Matcher must satisfy.
+type gomegaMatcher interface {
+	Match(actual interface{}) (success bool, err error)
+	FailureMessage(actual interface{}) (message string)
+	NegatedFailureMessage(actual interface{}) (message string)
+}
+
+var _ gomegaMatcher = &Matcher{}
+
+func TestLooksLike(t *testing.T) {
+	matcher := LooksLike(lookslike.Map{"foo": "bar", "n": lookslike.IsNumeric})
+
+	ok, err := matcher.Match(lookslike.Map{"foo": "bar", "n": 1})
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	bad := lookslike.Map{"foo": "baz", "n": 1}
+	ok, err = matcher.Match(bad)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	msg := matcher.FailureMessage(bad)
+	assert.Contains(t, msg, "found 1 errors")
+	assert.Contains(t, msg, "foo")
+	assert.NotContains(t, msg, "PASS")
+
+	assert.Contains(t, matcher.NegatedFailureMessage(bad), "not to look like the schema")
+}
+
+func TestLooksLikeValidator(t *testing.T) {
+	matcher := LooksLike(lookslike.Strict(lookslike.MustCompile(lookslike.Map{"foo": "bar"})))
+
+	ok, err := matcher.Match(lookslike.Map{"foo": "bar", "extra": 1})
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestLooksLikeInvalidSchema(t *testing.T) {
+	matcher := LooksLike(42)
+	_, err := matcher.Match(lookslike.Map{})
+	assert.Error(t, err)
+	assert.Equal(t, err.Error(), matcher.FailureMessage(lookslike.Map{}))
+}
@@ -0,0 +1,82 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package httplike helps validate HTTP responses with lookslike, which is handy for API tests.
+package httplike
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/elastic/lookslike/lookslike"
+)
+
+// ResponseCheck validates some aspect of an HTTP response other than its body, such as its status code.
+type ResponseCheck func(resp *http.Response) *lookslike.Results
+
+// StatusPath is the path Status records its results at. The leading @ distinguishes it from keys in the body.
+var StatusPath = lookslike.Path{}.ExtendMap("@status")
+
+// HeadersPath is the path beneath which Header records its results, one key per header name.
+var HeadersPath = lookslike.Path{}.ExtendMap("@headers")
+
+// Status checks the status code of the response, an int, with the given IsDef, recording the results at
+// StatusPath.
+func Status(def lookslike.IsDef) ResponseCheck {
+	return func(resp *http.Response) *lookslike.Results {
+		return def.Check(StatusPath, resp.StatusCode, true)
+	}
+}
+
+// Header checks the first value of the given header, a string, with the given IsDef, recording the results
+// beneath HeadersPath at the canonical form of the header's name, e.g. @headers.Content-Type. A header that
+// isn't set is treated as a missing key, so it fails unless the IsDef is Optional.
+func Header(name string, def lookslike.IsDef) ResponseCheck {
+	name = http.CanonicalHeaderKey(name)
+	return func(resp *http.Response) *lookslike.Results {
+		values, exists := resp.Header[name]
+		var value interface{}
+		if exists && len(values) > 0 {
+			value = values[0]
+		}
+		return def.Check(HeadersPath.ExtendMap(name), value, exists)
+	}
+}
+
+// ValidateResponse decodes the JSON body of the response and validates it with the given Validator, along with
+// any additional checks of the response, such as Status and Header, whose results are merged into the body's.
+// The body is read fully and then replaced with an unread copy, so callers may read it again afterwards.
+// Errors reading or decoding the body are returned as the error rather than recorded in the Results.
+func ValidateResponse(resp *http.Response, v lookslike.Validator, checks ...ResponseCheck) (*lookslike.Results, error) {
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := lookslike.ValidateJSON(bytes.NewReader(body), v)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, check := range checks {
+		results.MergeUnderPrefix(lookslike.Path{}, check(resp))
+	}
+	return results, nil
+}
@@ -0,0 +1,73 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package httplike
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/lookslike/lookslike"
+)
+
+func response(status int, contentType string, body string) *http.Response {
+	rec := httptest.NewRecorder()
+	if contentType != "" {
+		rec.Header().Set("Content-Type", contentType)
+	}
+	rec.WriteHeader(status)
+	rec.WriteString(body)
+	return rec.Result()
+}
+
+func TestValidateResponse(t *testing.T) {
+	validator := lookslike.MustCompile(lookslike.Map{"id": 1.0, "name": lookslike.IsString})
+	checks := []ResponseCheck{
+		Status(lookslike.IsEqual(http.StatusOK)),
+		Header("content-type", lookslike.IsStringContaining("json")),
+	}
+
+	resp := response(http.StatusOK, "application/json", `{"id": 1, "name": "foo"}`)
+	res, err := ValidateResponse(resp, validator, checks...)
+	require.NoError(t, err)
+	assert.True(t, res.Valid)
+	assert.Contains(t, res.Fields, "@status")
+	assert.Contains(t, res.Fields, "@headers.Content-Type")
+
+	// The body can still be read
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"id": 1, "name": "foo"}`, string(body))
+
+	res, err = ValidateResponse(response(http.StatusNotFound, "", `{"id": 2}`), validator, checks...)
+	require.NoError(t, err)
+	assert.False(t, res.Valid)
+	errs := res.DetailedErrors().Fields
+	assert.Len(t, errs, 4)
+	assert.Equal(t, lookslike.KeyMissingVR, errs["@headers.Content-Type"][0])
+	assert.Contains(t, errs, "@status")
+	assert.Contains(t, errs, "id")
+	assert.Contains(t, errs, "name")
+
+	_, err = ValidateResponse(response(http.StatusOK, "", `not json`), validator)
+	assert.Error(t, err)
+}
@@ -18,20 +18,44 @@
 package lookslike
 
 import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
 	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 // IsEqual tests that the given object is equal to the actual object.
+// If either side is a json.Number, as produced by json.Decoder.UseNumber, and the other is numeric,
+// the two are compared numerically as they would be with IsNumEqual. Otherwise numbers of differing
+// types are not equal, so IsEqual(5) does not match 5.0.
 func IsEqual(to interface{}) IsDef {
+	// There's no type to look up a registered handler for with a nil value
+	if to == nil {
+		return IsDeepEqual(to)
+	}
+
 	toV := reflect.ValueOf(to)
+	equalChecksLock.RLock()
 	isDefFactory, ok := equalChecks[toV.Type()]
+	equalChecksLock.RUnlock()
 
 	// If there are no handlers declared explicitly for this type we perform a deep equality check
 	if !ok {
+		if _, isNum := toFloat64(to); isNum {
+			return isEqualToNumber(to)
+		}
 		return IsDeepEqual(to)
 	}
 
@@ -46,9 +70,32 @@ func IsEqual(to interface{}) IsDef {
 // KeyPresent checks that the given key is in the map, even if it has a nil value.
 var KeyPresent = IsDef{Name: "check key present"}
 
-// KeyMissing checks that the given key is not present defined.
+// KeyMissing checks that the given key is not present at all, failing if it is, even with a nil value.
+// Use it for keys that must have been stripped, such as deprecated fields.
+//
+// With Strict a present key is reported as failing KeyMissing rather than as unexpected, since it has a
+// result. Wildcard paths, such as items.[*].legacy, check the key in every element matched.
 var KeyMissing = IsDef{Name: "check key not present", CheckKeyMissing: true}
 
+// IsAbsent is an alias of KeyMissing, checking that the given key is not present at all.
+var IsAbsent = KeyMissing
+
+// Capture passes for any value, storing it into the given pointer so it can be used in assertions after
+// validation, such as checking that a captured start time is before a captured end time. The name is only
+// used to describe the IsDef. Like other IsDefs the key must be present, wrap Capture in Optional to allow
+// its absence, in which case the pointer is left untouched.
+//
+// Each time the IsDef is checked it overwrites the pointer, so when it is used at a wildcard path, or at more
+// than one path, the last value checked wins. Wildcard matches are checked in path order, but the order in
+// which distinct keys of a schema are checked is unspecified, so avoid sharing a pointer between keys.
+// Since it writes to the pointer, a Validator using Capture must not be run concurrently.
+func Capture(name string, into *interface{}) IsDef {
+	return Is("capture "+name, func(path Path, v interface{}) *Results {
+		*into = v
+		return ValidResult(path)
+	})
+}
+
 func init() {
 	MustRegisterEqual(IsEqualToTime)
 }
@@ -70,6 +117,9 @@ func MustRegisterEqual(fn interface{}) {
 
 var equalChecks = map[reflect.Type]reflect.Value{}
 
+// equalChecksLock guards equalChecks, allowing RegisterEqual to be called concurrently with IsEqual.
+var equalChecksLock sync.RWMutex
+
 // RegisterEqual takes a function of the form fn(v someType) IsDef
 // and registers it to check equality for that type.
 func RegisterEqual(fn interface{}) error {
@@ -90,6 +140,8 @@ func RegisterEqual(fn interface{}) error {
 	}
 
 	inT := fnT.In(0)
+	equalChecksLock.Lock()
+	defer equalChecksLock.Unlock()
 	if _, ok := equalChecks[inT]; ok {
 		return InvalidEqualFnError{fmt.Sprintf("Duplicate Equal FN for type %v encountered!", inT)}
 	}
@@ -124,13 +176,182 @@ func IsDeepEqual(to interface{}) IsDef {
 		return SimpleResult(
 			path,
 			false,
-			fmt.Sprintf("objects not equal: actual(%T(%v)) != expected(%T(%v))", v, v, to, to),
+			"objects not equal: actual(%T(%v)) != expected(%T(%v))", v, v, to, to,
+		)
+	})
+}
+
+// IsEqualIgnoring deep compares the value to the expected Map, like IsEqual, except that the values at the
+// given paths are not compared, nor required to be present in either. This is useful for responses containing
+// volatile fields such as timestamps and generated IDs. The paths use the syntax of ParsePath, including
+// wildcards, are relative to the value being checked, and ignore everything beneath them too. Differences
+// are reported as described by IsEqualToDocument. IsEqualIgnoring panics if a path cannot be parsed.
+func IsEqualIgnoring(expected Map, ignorePaths ...string) IsDef {
+	ignored := make([]Path, len(ignorePaths))
+	for i, p := range ignorePaths {
+		ignored[i] = MustParsePath(p)
+	}
+
+	return Is("equals ignoring "+strings.Join(ignorePaths, ", "), func(path Path, v interface{}) *Results {
+		results := ValidResult(path)
+		compareDocument(path, Path{}, v, expected, ignored, results)
+		return results
+	})
+}
+
+// compareDocument records the differences between actual and expected into results, where path is the
+// absolute path and rel the path relative to the root of the comparison, against which ignored paths match.
+func compareDocument(path Path, rel Path, actual interface{}, expected interface{}, ignored []Path, results *Results) {
+	if isIgnored(rel, ignored) {
+		results.merge(ValidResult(path))
+		return
+	}
+
+	expectedKind := reflect.Invalid
+	if expected != nil {
+		expectedKind = reflect.TypeOf(expected).Kind()
+	}
+
+	switch expectedKind {
+	case reflect.Map:
+		expectedMap := interfaceToMap(expected)
+		actualMap, errorResults := isMapCheck(path, actual)
+		if errorResults != nil {
+			results.merge(errorResults)
+			return
+		}
+
+		for k, expectedV := range expectedMap {
+			actualV, exists := actualMap[k]
+			if !exists {
+				childRel := rel.ExtendMap(k)
+				if !isIgnored(childRel, ignored) {
+					results.merge(KeyMissingResult(path.ExtendMap(k)))
+				}
+				continue
+			}
+			compareDocument(path.ExtendMap(k), rel.ExtendMap(k), actualV, expectedV, ignored, results)
+		}
+		for k := range actualMap {
+			if _, exists := expectedMap[k]; !exists && !isIgnored(rel.ExtendMap(k), ignored) {
+				results.merge(StrictFailureResult(path.ExtendMap(k)))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		expectedSlice := sliceToSliceOfInterfaces(expected)
+		actualSlice, errorResults := isSliceCheck(path, actual)
+		if errorResults != nil {
+			results.merge(errorResults)
+			return
+		}
+
+		if len(actualSlice) != len(expectedSlice) {
+			results.merge(SimpleResult(path, false, "expected slice of length %d, got length %d", len(expectedSlice), len(actualSlice)))
+			return
+		}
+		for idx := range expectedSlice {
+			compareDocument(path.ExtendSlice(idx), rel.ExtendSlice(idx), actualSlice[idx], expectedSlice[idx], ignored, results)
+		}
+	default:
+		if equal, bothNumeric := numbersEqual(actual, expected); bothNumeric {
+			if !equal {
+				results.merge(SimpleResult(path, false, "expected %v, got %v", expected, actual))
+			} else {
+				results.merge(ValidResult(path))
+			}
+			return
+		}
+
+		if actual != nil && expected != nil && reflect.TypeOf(actual) != reflect.TypeOf(expected) {
+			results.merge(SimpleResult(
+				path,
+				false,
+				"type mismatch: expected %T %#v, got %T %#v", expected, expected, actual, actual,
+			))
+			return
+		}
+		results.merge(IsEqual(expected).Check(path, actual, true))
+	}
+}
+
+// isIgnored returns true if the given path, or one of its ancestors, matches one of the ignored paths.
+func isIgnored(rel Path, ignored []Path) bool {
+	for _, ignore := range ignored {
+		if len(rel) >= len(ignore) && rel[:len(ignore)].matches(ignore) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEqualFunc checks equality using the given function, which is called with the actual value first and the
+// expected value second. This allows domain specific notions of equality, such as ignoring case or trailing
+// whitespace, without writing a ValueValidator from scratch.
+func IsEqualFunc(expected interface{}, eq func(a, b interface{}) bool) IsDef {
+	return Is("equals", func(path Path, v interface{}) *Results {
+		if eq(v, expected) {
+			return ValidResult(path)
+		}
+		return SimpleResult(
+			path,
+			false,
+			"objects not equal: actual(%T(%v)) != expected(%T(%v))", v, v, expected, expected,
+		)
+	})
+}
+
+// isEqualToNumber is IsDeepEqual, except that json.Numbers are compared numerically against other numbers.
+func isEqualToNumber(to interface{}) IsDef {
+	_, toIsJSONNum := to.(json.Number)
+	return Is("equals", func(path Path, v interface{}) *Results {
+		_, vIsJSONNum := v.(json.Number)
+
+		var equal bool
+		if toIsJSONNum || vIsJSONNum {
+			equal, _ = numbersEqual(v, to)
+		} else {
+			equal = reflect.DeepEqual(v, to)
+		}
+
+		if equal {
+			if toIsJSONNum != vIsJSONNum {
+				return coercedResult(path, v, fmt.Sprintf("%T", to))
+			}
+			return ValidResult(path)
+		}
+		return SimpleResult(
+			path,
+			false,
+			"objects not equal: actual(%T(%v)) != expected(%T(%v))", v, v, to, to,
 		)
 	})
 }
 
+// IsNumEqual tests that the actual value is numerically equal to the given number, regardless of either's
+// type. Any of go's numeric types and json.Number are accepted, so IsNumEqual(5) matches int8(5), 5.0,
+// and json.Number("5").
+// Integers, including json.Numbers holding integers, are compared exactly as int64s. Other values are
+// compared as float64s, so very large integers that don't fit in an int64 may lose precision.
+func IsNumEqual(to interface{}) IsDef {
+	return Is("numerically equals", func(path Path, v interface{}) *Results {
+		equal, ok := numbersEqual(v, to)
+		if !ok {
+			return SimpleResult(path, false, "Expected a number to compare to %v, got '%v' which is a %T", to, v, v)
+		}
+
+		if !equal {
+			return SimpleResult(path, false, "actual(%v) != expected(%v)", v, to)
+		}
+
+		if reflect.TypeOf(v) != reflect.TypeOf(to) {
+			return coercedResult(path, v, fmt.Sprintf("%T", to))
+		}
+		return ValidResult(path)
+	})
+}
+
 // IsArrayOf validates that the array at the given key is an array of objects all validatable
-// via the given Validator.
+// via the given Validator. To apply a single IsDef to every element of a slice, see IsSliceOf.
 func IsArrayOf(validator Validator) IsDef {
 	return Is("array of maps", func(path Path, v interface{}) *Results {
 		vArr, isArr := v.([]Map)
@@ -150,183 +371,1575 @@ func IsArrayOf(validator Validator) IsDef {
 	})
 }
 
-// IsAny takes a variable number of IsDef's and combines them with a logical OR. If any single definition
-// matches the key will be marked as valid.
-func IsAny(of ...IsDef) IsDef {
-	names := make([]string, len(of))
-	for i, def := range of {
-		names[i] = def.Name
+// Ref validates the value with the given Validator, reporting its results beneath the value's path. It is
+// how a Validator, such as the one passed to the build function of CompileRecursive, is applied to a nested
+// value. Unlike IsArrayOf it applies to a single value of any type.
+func Ref(validator Validator) IsDef {
+	return Is("ref", func(path Path, v interface{}) *Results {
+		results := NewResults()
+		results.MergeUnderPrefix(path, validator(v))
+		return results
+	})
+}
+
+// isSliceCheck is a helper for IsDefs that must assert that the value is a slice or array first.
+func isSliceCheck(path Path, v interface{}) (elems []interface{}, errorResults *Results) {
+	if v == nil {
+		return nil, SimpleResult(path, false, "Expected slice at given path, got nil")
+	}
+	kind := reflect.TypeOf(v).Kind()
+	if kind != reflect.Slice && kind != reflect.Array {
+		return nil, SimpleResult(path, false, "Expected slice at given path, got %T", v)
 	}
-	isName := fmt.Sprintf("either %#v", names)
 
-	return Is(isName, func(path Path, v interface{}) *Results {
-		for _, def := range of {
-			vr := def.Check(path, v, true)
-			if vr.Valid {
-				return vr
-			}
+	return sliceToSliceOfInterfaces(v), nil
+}
+
+// IsSliceOf validates that the value at the given key is a slice (or array), and that every element
+// in it matches the given IsDef. Failures are reported per-index. Empty slices pass, combine this
+// with a length or emptiness check if that is undesirable.
+func IsSliceOf(elemDef IsDef) IsDef {
+	return Is("slice of", func(path Path, v interface{}) *Results {
+		elems, errorResults := isSliceCheck(path, v)
+		if errorResults != nil {
+			return errorResults
 		}
 
-		return SimpleResult(
-			path,
-			false,
-			fmt.Sprintf("Value was none of %#v, actual value was %#v", names, v),
-		)
+		if len(elems) == 0 {
+			return ValidResult(path)
+		}
+
+		results := NewResults()
+		for idx, elem := range elems {
+			results.merge(elemDef.Check(path.ExtendSlice(idx), elem, true))
+		}
+
+		return results
 	})
 }
 
-// IsUnique instances are used in multiple spots, flagging a value as being in error if it's seen across invocations.
-// To use it, assign IsUnique to a variable, then use that variable multiple times in a Map.
-func IsUnique() IsDef {
-	return ScopedIsUnique().IsUniqueTo("")
-}
+// IsSliceContainingAll validates that the value is a slice containing, in any order, an element matching
+// each of the given IsDefs, with each element used to satisfy at most one of them. This suits result sets
+// whose order isn't guaranteed. Elements are assigned to IsDefs with a maximum bipartite matching, so an
+// element matching several IsDefs doesn't starve the others. If some IsDefs can't be matched the failure
+// lists them. Matched elements have their results recorded at their index, so when combined with Strict
+// any elements that matched none of the IsDefs are reported as unexpected.
+func IsSliceContainingAll(defs ...IsDef) IsDef {
+	return Is("slice containing all", func(path Path, v interface{}) *Results {
+		elems, errorResults := isSliceCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
 
-// UniqScopeTracker is represents the tracking data for invoking IsUniqueTo.
-type UniqScopeTracker map[interface{}]string
+		// candidates[d] holds the results of checking def d against each element it matches
+		candidates := make([]map[int]*Results, len(defs))
+		for d, def := range defs {
+			candidates[d] = map[int]*Results{}
+			for e, elem := range elems {
+				if res := def.Check(path.ExtendSlice(e), elem, true); res.Valid {
+					candidates[d][e] = res
+				}
+			}
+		}
 
-// IsUniqueTo validates that the given value is only ever seen within a single namespace.
-func (ust UniqScopeTracker) IsUniqueTo(namespace string) IsDef {
-	return Is("unique", func(path Path, v interface{}) *Results {
-		for trackerK, trackerNs := range ust {
-			hasNamespace := len(namespace) > 0
-			if reflect.DeepEqual(trackerK, v) && (!hasNamespace || namespace != trackerNs) {
-				return SimpleResult(path, false, "Value '%v' is repeated", v)
+		// elemOwner maps elements to the def they're assigned to, found with augmenting paths
+		elemOwner := make(map[int]int, len(elems))
+		var assign func(d int, visited map[int]bool) bool
+		assign = func(d int, visited map[int]bool) bool {
+			for e := 0; e < len(elems); e++ {
+				if _, ok := candidates[d][e]; !ok || visited[e] {
+					continue
+				}
+				visited[e] = true
+				if owner, taken := elemOwner[e]; !taken || assign(owner, visited) {
+					elemOwner[e] = d
+					return true
+				}
 			}
+			return false
 		}
 
-		ust[v] = namespace
-		return ValidResult(path)
-	})
-}
+		var unmatched []string
+		for d, def := range defs {
+			if !assign(d, map[int]bool{}) {
+				unmatched = append(unmatched, def.Name)
+			}
+		}
 
-func ScopedIsUnique() UniqScopeTracker {
-	return UniqScopeTracker{}
+		results := NewResults()
+		if len(unmatched) > 0 {
+			results.merge(SimpleResult(
+				path,
+				false,
+				"no distinct element matched %d of %d definitions: %#v", len(unmatched), len(defs), unmatched,
+			))
+		} else {
+			results.merge(ValidResult(path))
+		}
+		for e, d := range elemOwner {
+			results.merge(candidates[d][e])
+		}
+		return results
+	})
 }
 
-// isStrCheck is a helper for IsDefs that must assert that the value is a string first.
-func isStrCheck(path Path, v interface{}) (str string, errorResults *Results) {
-	strV, ok := v.(string)
+// IsElementWhere validates that the value is a slice, finds the elements matching predicate, and checks each
+// of them with valueDef, which is useful for slices keyed by a field within their elements rather than by
+// position. Predicates and valueDefs checking fields of map elements can be built with Ref, e.g.
+//
+//	IsElementWhere(Ref(MustCompile(Map{"id": "x"})), Ref(MustCompile(Map{"status": "ok"})))
+//
+// If no element matches the predicate validation fails. If several do every one of them must match valueDef,
+// combine this with another check if the match should be unique. Results are recorded at the index of each
+// matching element.
+func IsElementWhere(predicate IsDef, valueDef IsDef) IsDef {
+	return Is(fmt.Sprintf("element where %s", predicate.Name), func(path Path, v interface{}) *Results {
+		elems, errorResults := isSliceCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
 
-	if !ok {
-		return "", SimpleResult(
-			path,
-			false,
-			fmt.Sprintf("Unable to convert '%v' to string", v),
-		)
-	}
+		results := NewResults()
+		matched := false
+		for idx, elem := range elems {
+			elemPath := path.ExtendSlice(idx)
+			if !predicate.Check(elemPath, elem, true).Valid {
+				continue
+			}
+			matched = true
+			results.merge(valueDef.Check(elemPath, elem, true))
+		}
 
-	return strV, nil
+		if !matched {
+			return SimpleResult(path, false, "no element of %d matched %s", len(elems), predicate.Name)
+		}
+		return results
+	})
 }
 
-// IsString checks that the given value is a string.
-var IsString = Is("is a string", func(path Path, v interface{}) *Results {
-	_, errorResults := isStrCheck(path, v)
+// IsSliceUnique validates that the value is a slice with no duplicate elements, using the same equality
+// semantics as IsEqual. Elements may be scalars, or maps and slices, which are compared deeply.
+// Note that this is unrelated to IsUnique, which checks for uniqueness across multiple keys.
+var IsSliceUnique = Is("slice with unique elements", func(path Path, v interface{}) *Results {
+	elems, errorResults := isSliceCheck(path, v)
 	if errorResults != nil {
 		return errorResults
 	}
 
+	for i, elem := range elems {
+		eq := IsEqual(elem)
+		for j := i + 1; j < len(elems); j++ {
+			if eq.Check(path, elems[j], true).Valid {
+				return SimpleResult(path, false, "Value %#v is duplicated at indices %d and %d", elem, i, j)
+			}
+		}
+	}
+
 	return ValidResult(path)
 })
 
-// IsNonEmptyString checks that the given value is a string and has a length > 1.
-var IsNonEmptyString = Is("is a non-empty string", func(path Path, v interface{}) *Results {
-	strV, errorResults := isStrCheck(path, v)
+// naturalLess orders numbers numerically and strings lexically. The second return value is
+// false if the two values are not both numbers or both strings.
+func naturalLess(a, b interface{}) (less bool, ok bool) {
+	if aStr, isStr := a.(string); isStr {
+		bStr, isStr := b.(string)
+		return aStr < bStr, isStr
+	}
+
+	aN, aOk := toFloat64(a)
+	bN, bOk := toFloat64(b)
+	return aN < bN, aOk && bOk
+}
+
+// IsSorted validates that the value is a slice of numbers or strings in ascending order.
+// Slices of length 0 or 1 are always sorted.
+var IsSorted = Is("is sorted", func(path Path, v interface{}) *Results {
+	elems, errorResults := isSliceCheck(path, v)
 	if errorResults != nil {
 		return errorResults
 	}
 
-	if len(strV) == 0 {
-		return SimpleResult(path, false, "String '%s' should not be empty", strV)
+	for i := 1; i < len(elems); i++ {
+		less, ok := naturalLess(elems[i], elems[i-1])
+		if !ok {
+			return SimpleResult(
+				path,
+				false,
+				"Cannot compare %#v (index %d) and %#v (index %d), only numbers and strings can be sorted naturally",
+				elems[i-1], i-1, elems[i], i,
+			)
+		}
+		if less {
+			return SimpleResult(path, false, "Index %d (%#v) is out of order with index %d (%#v)", i-1, elems[i-1], i, elems[i])
+		}
 	}
 
 	return ValidResult(path)
 })
 
-// IsStringMatching checks whether a value matches the given regexp.
-func IsStringMatching(regexp *regexp.Regexp) IsDef {
-	return Is("is string matching regexp", func(path Path, v interface{}) *Results {
-		strV, errorResults := isStrCheck(path, v)
+// IsSortedBy validates that the value is a slice sorted according to the given less function,
+// which has the same semantics as the one used by sort.Slice.
+func IsSortedBy(less func(a, b interface{}) bool) IsDef {
+	return Is("is sorted by", func(path Path, v interface{}) *Results {
+		elems, errorResults := isSliceCheck(path, v)
 		if errorResults != nil {
 			return errorResults
 		}
 
-		if !regexp.MatchString(strV) {
-			return SimpleResult(
-				path,
-				false,
-				fmt.Sprintf("String '%s' did not match regexp %s", strV, regexp.String()),
-			)
+		for i := 1; i < len(elems); i++ {
+			if less(elems[i], elems[i-1]) {
+				return SimpleResult(path, false, "Index %d (%#v) is out of order with index %d (%#v)", i-1, elems[i-1], i, elems[i])
+			}
 		}
 
 		return ValidResult(path)
 	})
 }
 
-// IsStringContaining validates that the the actual value contains the specified substring.
-func IsStringContaining(needle string) IsDef {
-	return Is("is string containing", func(path Path, v interface{}) *Results {
-		strV, errorResults := isStrCheck(path, v)
+// sliceContains reports whether any element of haystack equals needle, using IsEqual semantics.
+func sliceContains(haystack []interface{}, needle interface{}) bool {
+	eq := IsEqual(needle)
+	for _, h := range haystack {
+		if eq.Check(Path{}, h, true).Valid {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSubsetOf validates that the value is a slice whose elements all appear in the given superset,
+// irrespective of order. Elements are compared with IsEqual semantics. This is a set comparison, so
+// duplicates are ignored: []int{1, 1} is a subset of Slice{1}.
+func IsSubsetOf(superset Slice) IsDef {
+	return Is("is subset of", func(path Path, v interface{}) *Results {
+		elems, errorResults := isSliceCheck(path, v)
 		if errorResults != nil {
 			return errorResults
 		}
 
-		if !strings.Contains(strV, needle) {
-			return SimpleResult(
-				path,
-				false,
-				fmt.Sprintf("String '%s' did not contain substring '%s'", strV, needle),
-			)
+		var extra []interface{}
+		for _, elem := range elems {
+			if !sliceContains(superset, elem) {
+				extra = append(extra, elem)
+			}
+		}
+
+		if len(extra) > 0 {
+			return SimpleResult(path, false, "Elements %#v are not in the expected superset %#v", extra, superset)
 		}
 
 		return ValidResult(path)
 	})
 }
 
-// IsDuration tests that the given value is a duration.
-var IsDuration = Is("is a duration", func(path Path, v interface{}) *Results {
-	if _, ok := v.(time.Duration); ok {
+// IsSupersetOf validates that the value is a slice containing every element of the given subset,
+// irrespective of order. Elements are compared with IsEqual semantics. As with IsSubsetOf this is a set
+// comparison, so each element of the subset need only appear once regardless of duplicates.
+func IsSupersetOf(subset Slice) IsDef {
+	return Is("is superset of", func(path Path, v interface{}) *Results {
+		elems, errorResults := isSliceCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		var missing []interface{}
+		for _, expected := range subset {
+			if !sliceContains(elems, expected) {
+				missing = append(missing, expected)
+			}
+		}
+
+		if len(missing) > 0 {
+			return SimpleResult(path, false, "Missing expected elements %#v", missing)
+		}
+
 		return ValidResult(path)
-	}
-	return SimpleResult(
-		path,
-		false,
-		fmt.Sprintf("Expected a time.duration, got '%v' which is a %T", v, v),
-	)
-})
+	})
+}
 
-// IsNil tests that a value is nil.
-var IsNil = Is("is nil", func(path Path, v interface{}) *Results {
+// isMapCheck is a helper for IsDefs that must assert that the value is a map with string keys first. Structs
+// are accepted as well, and converted as described in the package documentation.
+func isMapCheck(path Path, v interface{}) (m Map, errorResults *Results) {
 	if v == nil {
-		return ValidResult(path)
+		return nil, SimpleResult(path, false, "Expected map at given path, got nil")
+	}
+	if m, isStruct := structMap(v); isStruct {
+		return m, nil
+	}
+	t := reflect.TypeOf(v)
+	if t.Kind() != reflect.Map || t.Key().Kind() != reflect.String {
+		return nil, SimpleResult(path, false, "Expected map with string keys at given path, got %T", v)
 	}
-	return SimpleResult(
-		path,
-		false,
-		fmt.Sprintf("Value %v is not nil", v),
-	)
-})
 
-func intGtChecker(than int) ValueValidator {
-	return func(path Path, v interface{}) *Results {
-		n, ok := v.(int)
-		if !ok {
-			msg := fmt.Sprintf("%v is a %T, but was expecting an int!", v, v)
-			return SimpleResult(path, false, msg)
-		}
+	return interfaceToMap(v), nil
+}
 
-		if n > than {
+// IsMapWithKeys validates that the value is a map containing all of the given keys, regardless of
+// the values they hold. Extra keys are permitted.
+func IsMapWithKeys(keys ...string) IsDef {
+	return Is("map with keys", func(path Path, v interface{}) *Results {
+		m, errorResults := isMapCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		var missing []string
+		for _, k := range keys {
+			if _, ok := m[k]; !ok {
+				missing = append(missing, k)
+			}
+		}
+
+		if len(missing) > 0 {
+			return SimpleResult(path, false, "Map is missing required keys %#v", missing)
+		}
+
+		return ValidResult(path)
+	})
+}
+
+// IsMapOf validates that the value is a map with string keys, and that every value in it matches the given
+// IsDef, which suits dictionaries whose keys can't be enumerated, such as maps keyed by ID. Failures are
+// reported per-key. Empty maps pass.
+func IsMapOf(valueDef IsDef) IsDef {
+	return Is("map of "+valueDef.Name, func(path Path, v interface{}) *Results {
+		m, errorResults := isMapCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		if len(m) == 0 {
 			return ValidResult(path)
 		}
 
+		results := NewResults()
+		for k, value := range m {
+			results.merge(valueDef.Check(path.ExtendMap(k), value, true))
+		}
+
+		return results
+	})
+}
+
+// IsMapWithKeysMatching validates that the value is a map with string keys, each of which matches the given
+// IsDef, such as IsStringMatchingStr(`^[a-z_]+$`) to require snake_case keys. Failures are reported at the
+// path of the map, once per offending key, prefixed with the key. Empty maps pass.
+func IsMapWithKeysMatching(keyDef IsDef) IsDef {
+	return Is("map with keys matching", func(path Path, v interface{}) *Results {
+		m, errorResults := isMapCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		results := ValidResult(path)
+		for _, k := range keys {
+			results.merge(keyDef.Check(path, k, true).withMessagePrefix(fmt.Sprintf("key '%s': ", k)))
+		}
+		return results
+	})
+}
+
+// IsAny takes a variable number of IsDef's and combines them with a logical OR. If any single definition
+// matches the key will be marked as valid.
+// Definitions are checked in order, and checking stops at the first one that matches, so later definitions
+// are not invoked at all in that case. This matters for definitions with side effects, like IsUnique.
+func IsAny(of ...IsDef) IsDef {
+	names := make([]string, len(of))
+	for i, def := range of {
+		names[i] = def.Name
+	}
+	isName := fmt.Sprintf("either %#v", names)
+
+	return Is(isName, func(path Path, v interface{}) *Results {
+		failures := make([]string, 0, len(of))
+		for _, def := range of {
+			vr := def.Check(path, v, true)
+			if vr.Valid {
+				return vr
+			}
+
+			vr.EachResult(func(_ Path, vr ValueResult) bool {
+				if !vr.Valid {
+					failures = append(failures, fmt.Sprintf("%s: %s", def.Name, vr.Message))
+				}
+				return true
+			})
+		}
+
 		return SimpleResult(
 			path,
 			false,
-			fmt.Sprintf("%v is not greater than %v", n, than),
+			"Value was none of %#v, actual value was %#v (%s)", names, v, strings.Join(failures, "; "),
+		)
+	})
+}
+
+// AnyOf is the logical OR counterpart to AllOf. It behaves exactly like IsAny, including stopping at the
+// first matching definition.
+func AnyOf(defs ...IsDef) IsDef {
+	return IsAny(defs...)
+}
+
+// AllOf takes a variable number of IsDef's and combines them with a logical AND. Every definition
+// is checked against the value, and the key is only marked as valid if all of them match.
+func AllOf(defs ...IsDef) IsDef {
+	names := make([]string, len(defs))
+	for i, def := range defs {
+		names[i] = def.Name
+	}
+	isName := fmt.Sprintf("all of %#v", names)
+
+	return Is(isName, func(path Path, v interface{}) *Results {
+		var failures []string
+		for _, def := range defs {
+			def.Check(path, v, true).EachResult(func(_ Path, vr ValueResult) bool {
+				if !vr.Valid {
+					failures = append(failures, fmt.Sprintf("%s: %s", def.Name, vr.Message))
+				}
+				return true
+			})
+		}
+
+		if len(failures) > 0 {
+			return SimpleResult(
+				path,
+				false,
+				"Value %#v failed %d of %d checks: %s", v, len(failures), len(defs), strings.Join(failures, "; "),
+			)
+		}
+
+		return ValidResult(path)
+	})
+}
+
+// If checks the value against thenDef if it matches condDef, and against elseDef otherwise, which makes it
+// possible to express conditional constraints on a single value. Failures name the branch that was taken,
+// e.g. "condition is a string matched, then: ...". To condition one field on the value of another, use When.
+func If(condDef IsDef, thenDef IsDef, elseDef IsDef) IsDef {
+	return Is(fmt.Sprintf("if %s then %s else %s", condDef.Name, thenDef.Name, elseDef.Name), func(path Path, v interface{}) *Results {
+		if condDef.Check(path, v, true).Valid {
+			return thenDef.Check(path, v, true).withMessagePrefix(fmt.Sprintf("condition %s matched, then: ", condDef.Name))
+		}
+		return elseDef.Check(path, v, true).withMessagePrefix(fmt.Sprintf("condition %s did not match, else: ", condDef.Name))
+	})
+}
+
+// IsOneOf checks that the actual value is equal to one of the allowed values, using the same
+// equality semantics as IsEqual.
+func IsOneOf(allowed ...interface{}) IsDef {
+	defs := make([]IsDef, len(allowed))
+	for i, a := range allowed {
+		defs[i] = IsEqual(a)
+	}
+
+	return Is("is one of", func(path Path, v interface{}) *Results {
+		for _, def := range defs {
+			if def.Check(path, v, true).Valid {
+				return ValidResult(path)
+			}
+		}
+
+		return SimpleResult(
+			path,
+			false,
+			"Value %#v was not one of the allowed values %#v", v, allowed,
+		)
+	})
+}
+
+// IsEnum checks that the actual value is one of the values of an enum type, given as a slice of its constants,
+// such as []Status{StatusActive, StatusInactive} for a `type Status string`. Since values decoded from JSON
+// are plain strings and float64s, they match a constant with the same underlying value, as "active" does
+// StatusActive, a coercion reported through WithCoercionWarnings. Failures list the valid values, using their
+// String method if the enum type implements fmt.Stringer. IsEnum panics if values isn't a slice or array.
+func IsEnum(values interface{}) IsDef {
+	rv := reflect.ValueOf(values)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		panic(fmt.Sprintf("IsEnum expects a slice of enum values, got %T", values))
+	}
+	enumType := rv.Type().Elem()
+	allowed := sliceToSliceOfInterfaces(values)
+
+	names := make([]string, len(allowed))
+	for i, a := range allowed {
+		if stringer, ok := a.(fmt.Stringer); ok {
+			names[i] = stringer.String()
+		} else {
+			names[i] = fmt.Sprintf("%v", a)
+		}
+	}
+
+	return Is(fmt.Sprintf("is a %s", enumType), func(path Path, v interface{}) *Results {
+		for _, a := range allowed {
+			if !enumValuesEqual(v, a) {
+				continue
+			}
+			if reflect.TypeOf(v) != reflect.TypeOf(a) {
+				return coercedResult(path, v, enumType.String())
+			}
+			return ValidResult(path)
+		}
+
+		return SimpleResult(
+			path,
+			false,
+			"Value '%v' is not a valid %s, expected one of: %s", v, enumType, strings.Join(names, ", "),
 		)
+	})
+}
+
+// enumValuesEqual reports whether the actual value equals the given enum constant, comparing strings and
+// numbers by their underlying values, so that a string equals a constant of a named string type.
+func enumValuesEqual(v interface{}, constant interface{}) bool {
+	if v == nil {
+		return false
+	}
+	if reflect.DeepEqual(v, constant) {
+		return true
+	}
+
+	av, cv := reflect.ValueOf(v), reflect.ValueOf(constant)
+	if av.Kind() == reflect.String && cv.Kind() == reflect.String {
+		return av.String() == cv.String()
 	}
+	equal, ok := numbersEqual(v, constant)
+	return ok && equal
 }
 
-// IsIntGt tests that a value is an int greater than.
-func IsIntGt(than int) IsDef {
-	return Is("greater than", intGtChecker(than))
+// IsUnique instances are used in multiple spots, flagging a value as being in error if it's seen across invocations.
+// To use it, assign IsUnique to a variable, then use that variable multiple times in a Map.
+func IsUnique() IsDef {
+	return ScopedIsUnique().IsUniqueTo("")
+}
+
+// UniqScopeTracker is represents the tracking data for invoking IsUniqueTo.
+type UniqScopeTracker map[interface{}]string
+
+// uniqTrackersLock guards all UniqScopeTrackers, so that IsUnique can be used concurrently.
+var uniqTrackersLock sync.Mutex
+
+// IsUniqueTo validates that the given value is only ever seen within a single namespace.
+func (ust UniqScopeTracker) IsUniqueTo(namespace string) IsDef {
+	return Is("unique", func(path Path, v interface{}) *Results {
+		uniqTrackersLock.Lock()
+		defer uniqTrackersLock.Unlock()
+
+		for trackerK, trackerNs := range ust {
+			hasNamespace := len(namespace) > 0
+			if reflect.DeepEqual(trackerK, v) && (!hasNamespace || namespace != trackerNs) {
+				return SimpleResult(path, false, "Value '%v' is repeated", v)
+			}
+		}
+
+		if v != nil && !reflect.TypeOf(v).Comparable() {
+			return SimpleResult(path, false, "Value of type %T can't be tracked for uniqueness", v)
+		}
+		ust[v] = namespace
+		return ValidResult(path)
+	})
+}
+
+func ScopedIsUnique() UniqScopeTracker {
+	return UniqScopeTracker{}
+}
+
+// isStrCheck is a helper for IsDefs that must assert that the value is a string first.
+func isStrCheck(path Path, v interface{}) (str string, errorResults *Results) {
+	strV, ok := v.(string)
+
+	if !ok {
+		return "", SimpleResult(
+			path,
+			false,
+			fmt.Sprintf("Unable to convert '%v' to string, it is a %T", v, v),
+		)
+	}
+
+	return strV, nil
+}
+
+// IsString checks that the given value is a string.
+var IsString = Is("is a string", func(path Path, v interface{}) *Results {
+	_, errorResults := isStrCheck(path, v)
+	if errorResults != nil {
+		return errorResults
+	}
+
+	return ValidResult(path)
+})
+
+// IsBool checks that the given value is a bool.
+var IsBool = Is("is a bool", func(path Path, v interface{}) *Results {
+	if _, ok := v.(bool); !ok {
+		return SimpleResult(path, false, "Expected a bool, got '%v' which is a %T", v, v)
+	}
+
+	return ValidResult(path)
+})
+
+// IsNumeric checks that the given value is any of go's int, uint, or float types. Valid json.Number values,
+// as produced by json.Decoder.UseNumber, are accepted too, but other numeric strings are not.
+var IsNumeric = Is("is numeric", func(path Path, v interface{}) *Results {
+	_, errorResults := isNumCheck(path, v)
+	if errorResults != nil {
+		return errorResults
+	}
+
+	return ValidResult(path)
+})
+
+// IsNonEmptyString checks that the given value is a string and has a length > 1.
+var IsNonEmptyString = Is("is a non-empty string", func(path Path, v interface{}) *Results {
+	strV, errorResults := isStrCheck(path, v)
+	if errorResults != nil {
+		return errorResults
+	}
+
+	if len(strV) == 0 {
+		return SimpleResult(path, false, "String '%s' should not be empty", strV)
+	}
+
+	return ValidResult(path)
+})
+
+// maxMessageValueLen is the maximum length of actual values quoted in failure messages.
+const maxMessageValueLen = 64
+
+// truncateForMessage shortens long strings so they don't overwhelm failure messages. It cuts on a rune
+// boundary, so that multi-byte characters aren't split.
+func truncateForMessage(s string) string {
+	if len(s) <= maxMessageValueLen {
+		return s
+	}
+	end := maxMessageValueLen
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end] + "..."
+}
+
+// IsStringMatching checks whether a value matches the given regexp.
+func IsStringMatching(regexp *regexp.Regexp) IsDef {
+	return Is("is string matching regexp", func(path Path, v interface{}) *Results {
+		strV, errorResults := isStrCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		if !regexp.MatchString(strV) {
+			return SimpleResult(
+				path,
+				false,
+				"String '%s' did not match regexp %s", truncateForMessage(strV), regexp.String(),
+			)
+		}
+
+		return ValidResult(path)
+	})
+}
+
+// IsStringMatchingStr is a convenience wrapper for IsStringMatching that compiles the given pattern.
+// It panics if the pattern is not a valid regexp, in the same way regexp.MustCompile does.
+func IsStringMatchingStr(pattern string) IsDef {
+	return IsStringMatching(regexp.MustCompile(pattern))
+}
+
+// IsStringCaptures validates that the actual value is a string matching the given regexp, and that the text
+// captured by its groups matches the IsDef given for each group number, as used by regexp.FindStringSubmatch.
+// Captured text is always a string, so for a version like v(\d+)\.(\d+), IsParseableAs can check that a group
+// is numeric. Use regexp.SubexpIndex to find the number of a named group. Groups that don't participate in
+// the match are treated as absent, so only Optional IsDefs accept them.
+// Failures are reported at the path of the string, prefixed with the number of the group that failed. It
+// panics if a group number is not within the regexp.
+func IsStringCaptures(re *regexp.Regexp, groupDefs map[int]IsDef) IsDef {
+	groups := make([]int, 0, len(groupDefs))
+	for group := range groupDefs {
+		if group < 0 || group > re.NumSubexp() {
+			panic(fmt.Sprintf("regexp %s has no capture group %d", re.String(), group))
+		}
+		groups = append(groups, group)
+	}
+	sort.Ints(groups)
+
+	return Is("is string with captures", func(path Path, v interface{}) *Results {
+		strV, errorResults := isStrCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		match := re.FindStringSubmatchIndex(strV)
+		if match == nil {
+			return SimpleResult(
+				path,
+				false,
+				"String '%s' did not match regexp %s", truncateForMessage(strV), re.String(),
+			)
+		}
+
+		results := ValidResult(path)
+		for _, group := range groups {
+			def := groupDefs[group]
+			start, end := match[2*group], match[2*group+1]
+			var groupRes *Results
+			if start >= 0 {
+				groupRes = def.Check(path, strV[start:end], true)
+			} else if !def.Optional || def.hasDefault {
+				groupRes = def.Check(path, nil, false)
+			} else {
+				continue
+			}
+			results.merge(groupRes.withMessagePrefix(fmt.Sprintf("capture group %d: ", group)))
+		}
+		return results
+	})
+}
+
+// IsStringContaining validates that the the actual value contains the specified substring.
+// As with strings.Contains, an empty needle matches any string.
+func IsStringContaining(needle string) IsDef {
+	return Is("is string containing", func(path Path, v interface{}) *Results {
+		strV, errorResults := isStrCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		if !strings.Contains(strV, needle) {
+			return SimpleResult(
+				path,
+				false,
+				fmt.Sprintf("String '%s' did not contain substring '%s'", truncateForMessage(strV), needle),
+			)
+		}
+
+		return ValidResult(path)
+	})
+}
+
+// IsEqualFold checks that the value is a string equal to the expected one under Unicode case-folding, as
+// with strings.EqualFold, so "OK" matches "ok". Non-string values fail.
+func IsEqualFold(expected string) IsDef {
+	return Is("equals (case-insensitive)", func(path Path, v interface{}) *Results {
+		strV, errorResults := isStrCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		if !strings.EqualFold(strV, expected) {
+			return SimpleResult(
+				path,
+				false,
+				"String '%s' did not equal '%s' (case-insensitive)", truncateForMessage(strV), expected,
+			)
+		}
+
+		return ValidResult(path)
+	})
+}
+
+// IsStringContainingFold is the case-insensitive equivalent of IsStringContaining.
+func IsStringContainingFold(needle string) IsDef {
+	lowerNeedle := strings.ToLower(needle)
+	return Is("is string containing (case-insensitive)", func(path Path, v interface{}) *Results {
+		strV, errorResults := isStrCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		if !strings.Contains(strings.ToLower(strV), lowerNeedle) {
+			return SimpleResult(
+				path,
+				false,
+				"String '%s' did not contain substring '%s' (case-insensitive)", truncateForMessage(strV), needle,
+			)
+		}
+
+		return ValidResult(path)
+	})
+}
+
+// isDurationCheck is a helper for IsDefs that must coerce the value to a time.Duration first.
+// Both time.Duration values and strings parseable by time.ParseDuration are accepted.
+func isDurationCheck(path Path, v interface{}) (d time.Duration, errorResults *Results) {
+	switch tv := v.(type) {
+	case time.Duration:
+		return tv, nil
+	case string:
+		d, err := time.ParseDuration(tv)
+		if err != nil {
+			return 0, SimpleResult(path, false, "not a duration: %v", err)
+		}
+		return d, nil
+	default:
+		return 0, SimpleResult(
+			path,
+			false,
+			"not a duration: expected a time.Duration or duration string, got '%v' which is a %T", v, v,
+		)
+	}
+}
+
+// IsDuration tests that the given value is a time.Duration, or a string parseable by time.ParseDuration
+// such as "30s".
+var IsDuration = Is("is a duration", func(path Path, v interface{}) *Results {
+	if _, errorResults := isDurationCheck(path, v); errorResults != nil {
+		return errorResults
+	}
+	return ValidResult(path)
+})
+
+// IsDurationGTE tests that the given value is a duration, as accepted by IsDuration, of at least min.
+func IsDurationGTE(min time.Duration) IsDef {
+	return Is("is a duration >=", func(path Path, v interface{}) *Results {
+		d, errorResults := isDurationCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		if d < min {
+			return SimpleResult(path, false, "duration too small: expected >= %v, got %v", min, d)
+		}
+
+		return ValidResult(path)
+	})
+}
+
+// IsTimeFormat tests that the given value is a time.Time, or a string that can be parsed with the given layout
+// via time.Parse.
+func IsTimeFormat(layout string) IsDef {
+	return Is("is a time", func(path Path, v interface{}) *Results {
+		switch tv := v.(type) {
+		case time.Time:
+			return ValidResult(path)
+		case string:
+			if _, err := time.Parse(layout, tv); err != nil {
+				return SimpleResult(path, false, "could not parse time with layout '%s': %v", layout, err)
+			}
+			return ValidResult(path)
+		default:
+			return SimpleResult(
+				path,
+				false,
+				"Expected a time.Time or time string with layout '%s', got '%v' which is a %T", layout, v, v,
+			)
+		}
+	})
+}
+
+// IsRFC3339 tests that the given value is a time.Time, or a string in RFC3339 format.
+var IsRFC3339 = IsTimeFormat(time.RFC3339)
+
+// IsNil tests that a value is nil. Typed nils, such as a (*T)(nil) stored in an interface{}, are
+// considered nil as well.
+//
+// Note that IsNil is about the value, not the key: the key must be present with a nil value. To allow
+// the key to be absent entirely use Optional instead, or Optional(IsNil) to allow either.
+var IsNil = Is("is nil", func(path Path, v interface{}) *Results {
+	if isNil(v) {
+		return ValidResult(path)
+	}
+	return SimpleResult(
+		path,
+		false,
+		fmt.Sprintf("Value %v is not nil", v),
+	)
+})
+
+// IsJSONNull tests that a key is present with an explicit null value, as decoded from JSON into an untyped nil,
+// which PATCH style APIs often use to mean "clear this field". A missing key fails, so it tells apart a null
+// field from an absent one, which Optional(IsNil) does not. Unlike IsNil, typed nils such as nil slices fail
+// too, since decoding JSON null into an interface{} never produces them, though nil pointers found at a path are
+// checked as nil, as described in the package documentation. Keys that are present
+// with a null value are visited by Strict like any other, so with it an unexpected null key fails as well.
+var IsJSONNull = Is("is JSON null", func(path Path, v interface{}) *Results {
+	if v == nil {
+		return ValidResult(path)
+	}
+	return SimpleResult(
+		path,
+		false,
+		"Expected JSON null, got '%v' which is a %T", v, v,
+	)
+})
+
+// IsNonNil tests that a value is present and not nil. Typed nils are treated as nil, as they are by IsNil.
+var IsNonNil = Is("is non-nil", func(path Path, v interface{}) *Results {
+	if !isNil(v) {
+		return ValidResult(path)
+	}
+	return SimpleResult(
+		path,
+		false,
+		"Value %v is nil", v,
+	)
+})
+
+func intGtChecker(than int) ValueValidator {
+	return func(path Path, v interface{}) *Results {
+		n, ok := v.(int)
+		if !ok {
+			msg := fmt.Sprintf("%v is a %T, but was expecting an int!", v, v)
+			return SimpleResult(path, false, msg)
+		}
+
+		if n > than {
+			return ValidResult(path)
+		}
+
+		return SimpleResult(
+			path,
+			false,
+			fmt.Sprintf("%v is not greater than %v", n, than),
+		)
+	}
+}
+
+// IsIntGt tests that a value is an int greater than.
+func IsIntGt(than int) IsDef {
+	return Is("greater than", intGtChecker(than))
+}
+
+// isNumCheck is a helper for IsDefs that must coerce the value to a number first.
+func isNumCheck(path Path, v interface{}) (n float64, errorResults *Results) {
+	n, ok := toFloat64(v)
+	if !ok {
+		return 0, SimpleResult(
+			path,
+			false,
+			"Expected a numeric value, got '%v' which is a %T", v, v,
+		)
+	}
+
+	return n, nil
+}
+
+// numCompareChecker builds a ValueValidator comparing the actual numeric value against the given one.
+func numCompareChecker(op string, to float64, cmp func(actual float64) bool) ValueValidator {
+	return func(path Path, v interface{}) *Results {
+		n, errorResults := isNumCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		if !cmp(n) {
+			return SimpleResult(path, false, "expected value %s %v, got %v", op, to, v)
+		}
+
+		return numValidResult(path, v)
+	}
+}
+
+// IsGT tests that a value is a number greater than the given one. Any of go's numeric types are accepted.
+func IsGT(n float64) IsDef {
+	return Is("greater than", numCompareChecker(">", n, func(actual float64) bool { return actual > n }))
+}
+
+// IsGTE tests that a value is a number greater than or equal to the given one.
+func IsGTE(n float64) IsDef {
+	return Is("greater than or equal to", numCompareChecker(">=", n, func(actual float64) bool { return actual >= n }))
+}
+
+// IsLT tests that a value is a number less than the given one.
+func IsLT(n float64) IsDef {
+	return Is("less than", numCompareChecker("<", n, func(actual float64) bool { return actual < n }))
+}
+
+// IsLTE tests that a value is a number less than or equal to the given one.
+func IsLTE(n float64) IsDef {
+	return Is("less than or equal to", numCompareChecker("<=", n, func(actual float64) bool { return actual <= n }))
+}
+
+// IsPositive tests that a value is a number greater than zero, as per IsGT(0).
+var IsPositive = Is("is positive", numCompareChecker(">", 0, func(actual float64) bool { return actual > 0 }))
+
+// IsNegative tests that a value is a number less than zero, as per IsLT(0).
+var IsNegative = Is("is negative", numCompareChecker("<", 0, func(actual float64) bool { return actual < 0 }))
+
+// IsNonNegative tests that a value is a number greater than or equal to zero, as per IsGTE(0).
+var IsNonNegative = Is("is non-negative", numCompareChecker(">=", 0, func(actual float64) bool { return actual >= 0 }))
+
+// IsNonPositive tests that a value is a number less than or equal to zero, as per IsLTE(0).
+var IsNonPositive = Is("is non-positive", numCompareChecker("<=", 0, func(actual float64) bool { return actual <= 0 }))
+
+// isIntegerCheck checks that the value is a number without a fractional part, returning it as a float64.
+func isIntegerCheck(path Path, v interface{}) (n float64, errorResults *Results) {
+	n, errorResults = isNumCheck(path, v)
+	if errorResults != nil {
+		return 0, errorResults
+	}
+
+	if math.IsInf(n, 0) || n != math.Trunc(n) {
+		return 0, SimpleResult(path, false, "expected an integer, got %v", v)
+	}
+
+	return n, nil
+}
+
+// IsInteger tests that a value is a number without a fractional part. Since numbers decoded from JSON are
+// float64s, IsNumeric accepts 3.5 for a field meant to hold a count or an ID, which IsInteger rejects, while
+// accepting 3.0. Any of go's numeric types are accepted, integer kinds always passing. See IsInt64 to also
+// check the range.
+var IsInteger = Is("is an integer", func(path Path, v interface{}) *Results {
+	if _, errorResults := isIntegerCheck(path, v); errorResults != nil {
+		return errorResults
+	}
+
+	return numValidResult(path, v)
+})
+
+// IsInt64 is like IsInteger, but additionally tests that the value is within the range of an int64.
+var IsInt64 = Is("is an int64", func(path Path, v interface{}) *Results {
+	if _, fits := toInt64(v); fits {
+		return numValidResult(path, v)
+	}
+
+	n, errorResults := isIntegerCheck(path, v)
+	if errorResults != nil {
+		return errorResults
+	}
+
+	// -2^63 is exactly representable as a float64 while 2^63-1 is not, hence the asymmetric comparison
+	if n < math.MinInt64 || n >= -math.MinInt64 {
+		return SimpleResult(path, false, "expected an integer within the range of an int64, got %v", v)
+	}
+
+	return numValidResult(path, v)
+})
+
+// IsBetween tests that a value is a number within the given range. Both bounds are inclusive, so
+// min <= actual <= max must hold.
+func IsBetween(min, max float64) IsDef {
+	return Is("between", betweenChecker(min, max))
+}
+
+// betweenChecker builds a ValueValidator checking that the actual numeric value is within the given inclusive range.
+func betweenChecker(min, max float64) ValueValidator {
+	return func(path Path, v interface{}) *Results {
+		n, errorResults := isNumCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		if n < min {
+			return SimpleResult(path, false, "expected value >= %v (lower bound of [%v, %v]), got %v", min, min, max, v)
+		}
+		if n > max {
+			return SimpleResult(path, false, "expected value <= %v (upper bound of [%v, %v]), got %v", max, min, max, v)
+		}
+
+		return numValidResult(path, v)
+	}
+}
+
+// IsPercentage tests that a value is a number between 0 and 100, as per IsBetween. Both bounds are valid
+// percentages, so 0 and 100 pass.
+var IsPercentage = Is("is a percentage", betweenChecker(0, 100))
+
+// IsRatio tests that a value is a number between 0 and 1, as per IsBetween. Both bounds are valid ratios,
+// so 0 and 1 pass.
+var IsRatio = Is("is a ratio", betweenChecker(0, 1))
+
+// IsBetweenExclusive is like IsBetween, but excludes both bounds, so min < actual < max must hold.
+func IsBetweenExclusive(min, max float64) IsDef {
+	return Is("between (exclusive)", func(path Path, v interface{}) *Results {
+		n, errorResults := isNumCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		if n <= min {
+			return SimpleResult(path, false, "expected value > %v (lower bound of (%v, %v)), got %v", min, min, max, v)
+		}
+		if n >= max {
+			return SimpleResult(path, false, "expected value < %v (upper bound of (%v, %v)), got %v", max, min, max, v)
+		}
+
+		return numValidResult(path, v)
+	})
+}
+
+// IsCloseTo tests that a value is a number within tolerance of the expected value, which is useful for
+// computed floating point values where IsEqual is too strict. NaN never matches. If either value is
+// infinite the values must be exactly equal, since the delta between them is not meaningful.
+func IsCloseTo(expected float64, tolerance float64) IsDef {
+	return Is("close to", func(path Path, v interface{}) *Results {
+		n, errorResults := isNumCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		if math.IsNaN(n) || math.IsNaN(expected) {
+			return SimpleResult(path, false, "NaN is never close to anything: expected %v, got %v", expected, v)
+		}
+
+		if math.IsInf(n, 0) || math.IsInf(expected, 0) {
+			if n == expected {
+				return numValidResult(path, v)
+			}
+			return SimpleResult(path, false, "expected %v, got %v", expected, v)
+		}
+
+		delta := math.Abs(n - expected)
+		if delta > tolerance {
+			return SimpleResult(
+				path,
+				false,
+				"expected %v (+/- %v), got %v (delta %v)", expected, tolerance, v, delta,
+			)
+		}
+
+		return numValidResult(path, v)
+	})
+}
+
+// isLenCheck is a helper for IsDefs that must determine the length of a value first. Strings, slices,
+// arrays, and maps are supported. As with the builtin len(), nil slices and maps have a length of 0.
+// An untyped nil has no length and is treated as a type mismatch.
+func isLenCheck(path Path, v interface{}) (length int, errorResults *Results) {
+	if v == nil {
+		return 0, SimpleResult(path, false, "Expected a string, slice, array, or map, got nil")
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return rv.Len(), nil
+	default:
+		return 0, SimpleResult(
+			path,
+			false,
+			"Expected a string, slice, array, or map, got '%v' which is a %T", v, v,
+		)
+	}
+}
+
+// IsLength tests that a string, slice, array, or map has exactly the given length.
+// See isLenCheck for details on how nil values are treated.
+func IsLength(n int) IsDef {
+	return Is("has length", func(path Path, v interface{}) *Results {
+		length, errorResults := isLenCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		if length != n {
+			return SimpleResult(path, false, "expected length %d, got %d", n, length)
+		}
+
+		return ValidResult(path)
+	})
+}
+
+// IsLengthGTE tests that a string, slice, array, or map has a length of at least n.
+func IsLengthGTE(n int) IsDef {
+	return Is("has length >=", func(path Path, v interface{}) *Results {
+		length, errorResults := isLenCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		if length < n {
+			return SimpleResult(path, false, "expected length >= %d, got %d", n, length)
+		}
+
+		return ValidResult(path)
+	})
+}
+
+// IsLengthLTE tests that a string, slice, array, or map has a length of at most n.
+func IsLengthLTE(n int) IsDef {
+	return Is("has length <=", func(path Path, v interface{}) *Results {
+		length, errorResults := isLenCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		if length > n {
+			return SimpleResult(path, false, "expected length <= %d, got %d", n, length)
+		}
+
+		return ValidResult(path)
+	})
+}
+
+// IsEmpty tests that a value is an empty string, slice, array, or map, or is nil.
+// Values of other types are neither empty nor non-empty, and fail both IsEmpty and IsNonEmpty.
+var IsEmpty = Is("is empty", func(path Path, v interface{}) *Results {
+	if isNil(v) {
+		return ValidResult(path)
+	}
+
+	length, errorResults := isLenCheck(path, v)
+	if errorResults != nil {
+		return errorResults
+	}
+
+	if length != 0 {
+		return SimpleResult(path, false, "expected %s to be empty, got length %d", reflect.TypeOf(v).Kind(), length)
+	}
+
+	return ValidResult(path)
+})
+
+// IsNonEmpty tests that a value is a string, slice, array, or map with at least one element.
+// Nil values are considered empty.
+var IsNonEmpty = Is("is non-empty", func(path Path, v interface{}) *Results {
+	if isNil(v) {
+		return SimpleResult(path, false, "expected a non-empty value, got nil")
+	}
+
+	length, errorResults := isLenCheck(path, v)
+	if errorResults != nil {
+		return errorResults
+	}
+
+	if length == 0 {
+		return SimpleResult(path, false, "expected %s to be non-empty, got length 0", reflect.TypeOf(v).Kind())
+	}
+
+	return ValidResult(path)
+})
+
+// IsKind tests that the value's reflect.Kind is the given one, e.g. reflect.Float64 to ensure
+// a decoded JSON number wasn't encoded as a string.
+func IsKind(k reflect.Kind) IsDef {
+	return Is(fmt.Sprintf("is of kind %s", k), func(path Path, v interface{}) *Results {
+		actual := reflect.Invalid
+		if v != nil {
+			actual = reflect.TypeOf(v).Kind()
+		}
+
+		if actual != k {
+			return SimpleResult(path, false, "expected kind %s, got %s (%T)", k, actual, v)
+		}
+
+		return ValidResult(path)
+	})
+}
+
+// IsType tests that the value has exactly the same type as the given example value.
+func IsType(example interface{}) IsDef {
+	expected := reflect.TypeOf(example)
+	return Is(fmt.Sprintf("is of type %v", expected), func(path Path, v interface{}) *Results {
+		actual := reflect.TypeOf(v)
+		if actual != expected {
+			return SimpleResult(path, false, "expected type %v, got %v", expected, actual)
+		}
+
+		return ValidResult(path)
+	})
+}
+
+var uuidMatcher = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// isUUIDCheck is a helper for IsDefs that must assert that the value is a canonical UUID string first.
+func isUUIDCheck(path Path, v interface{}) (uuid string, errorResults *Results) {
+	strV, errorResults := isStrCheck(path, v)
+	if errorResults != nil {
+		return "", errorResults
+	}
+
+	if !uuidMatcher.MatchString(strV) {
+		return "", SimpleResult(path, false, "String '%s' is not a UUID in canonical 8-4-4-4-12 hex form", truncateForMessage(strV))
+	}
+
+	return strV, nil
+}
+
+// IsParseableAs tests that the value is a string accepted by the given parse function, which returns an error
+// for strings it rejects. This validates strings holding domain specific types, such as enums or custom
+// formats, by reusing the code that parses them. Failures include the error returned by parse.
+func IsParseableAs(parse func(string) error) IsDef {
+	return Is("is parseable", func(path Path, v interface{}) *Results {
+		strV, errorResults := isStrCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		if err := parse(strV); err != nil {
+			return SimpleResult(path, false, "could not parse '%s': %v", truncateForMessage(strV), err)
+		}
+
+		return ValidResult(path)
+	})
+}
+
+// isNumericStrCheck parses the value as per IsNumericString, returning the number it holds.
+func isNumericStrCheck(path Path, v interface{}) (n float64, errorResults *Results) {
+	strV, errorResults := isStrCheck(path, v)
+	if errorResults != nil {
+		return 0, errorResults
+	}
+
+	n, err := strconv.ParseFloat(strV, 64)
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, SimpleResult(path, false, "String '%s' is not parseable as a number", truncateForMessage(strV))
+	}
+
+	return n, nil
+}
+
+// IsNumericString tests that the value is a string holding a finite number, such as "42" or "-1.5e3", as some
+// APIs encode numbers to avoid losing precision. Strings such as "NaN" and "Inf" are rejected, as are numbers
+// that aren't strings; see IsNumeric for those.
+var IsNumericString = Is("is a numeric string", func(path Path, v interface{}) *Results {
+	if _, errorResults := isNumericStrCheck(path, v); errorResults != nil {
+		return errorResults
+	}
+
+	return ValidResult(path)
+})
+
+// IsNumericStringEqual tests that the value is a numeric string, as per IsNumericString, holding a number equal
+// to the given one, so "42", "42.0" and "4.2e1" all equal 42.
+func IsNumericStringEqual(to float64) IsDef {
+	return Is("numeric string equals", func(path Path, v interface{}) *Results {
+		n, errorResults := isNumericStrCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		if n != to {
+			return SimpleResult(path, false, "String '%v' holds %v, expected %v", v, n, to)
+		}
+
+		return ValidResult(path)
+	})
+}
+
+// IsUUID tests that the value is a string containing a UUID in its canonical 8-4-4-4-12 hex form.
+var IsUUID = Is("is a UUID", func(path Path, v interface{}) *Results {
+	if _, errorResults := isUUIDCheck(path, v); errorResults != nil {
+		return errorResults
+	}
+
+	return ValidResult(path)
+})
+
+// IsUUIDVersion tests that the value is a UUID, as per IsUUID, with the given version.
+func IsUUIDVersion(version int) IsDef {
+	return Is(fmt.Sprintf("is a v%d UUID", version), func(path Path, v interface{}) *Results {
+		uuid, errorResults := isUUIDCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		// The version is the first hex digit of the third group
+		actual, _ := strconv.ParseInt(uuid[14:15], 16, 0)
+		if int(actual) != version {
+			return SimpleResult(path, false, "UUID '%s' is version %d, expected version %d", uuid, actual, version)
+		}
+
+		return ValidResult(path)
+	})
+}
+
+// IsEmail tests that the value is a string holding a bare email address, such as "user@example.net", as
+// parsed by net/mail.ParseAddress. Addresses with display names, like "User <user@example.net>", are rejected.
+// Only the syntax of the address is validated, not whether the domain exists or can receive mail.
+var IsEmail = Is("is an email address", func(path Path, v interface{}) *Results {
+	strV, errorResults := isStrCheck(path, v)
+	if errorResults != nil {
+		return errorResults
+	}
+
+	addr, err := mail.ParseAddress(strV)
+	if err != nil {
+		return SimpleResult(path, false, "String '%s' is not an email address: %v", truncateForMessage(strV), err)
+	}
+	if addr.Address != strV {
+		return SimpleResult(path, false, "String '%s' is not a bare email address", truncateForMessage(strV))
+	}
+
+	return ValidResult(path)
+})
+
+// isURLCheck is a helper for IsDefs that must assert that the value is an absolute URL string first.
+func isURLCheck(path Path, v interface{}) (u *url.URL, errorResults *Results) {
+	strV, errorResults := isStrCheck(path, v)
+	if errorResults != nil {
+		return nil, errorResults
+	}
+
+	u, err := url.Parse(strV)
+	if err != nil {
+		return nil, SimpleResult(path, false, "could not parse URL: %v", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, SimpleResult(path, false, "URL '%s' must have both a scheme and a host", truncateForMessage(strV))
+	}
+
+	return u, nil
+}
+
+// IsURL tests that the value is a string that can be parsed by net/url.Parse and has both a scheme and a host.
+var IsURL = Is("is a URL", func(path Path, v interface{}) *Results {
+	if _, errorResults := isURLCheck(path, v); errorResults != nil {
+		return errorResults
+	}
+
+	return ValidResult(path)
+})
+
+// IsURLWithScheme tests that the value is a URL, as per IsURL, with one of the given schemes.
+// Schemes are compared case-insensitively.
+func IsURLWithScheme(schemes ...string) IsDef {
+	return Is(fmt.Sprintf("is a URL with scheme %v", schemes), func(path Path, v interface{}) *Results {
+		u, errorResults := isURLCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		for _, s := range schemes {
+			if strings.EqualFold(u.Scheme, s) {
+				return ValidResult(path)
+			}
+		}
+
+		return SimpleResult(path, false, "URL scheme '%s' is not allowed, expected one of %v", u.Scheme, schemes)
+	})
+}
+
+// isIPCheck is a helper for IsDefs that must assert that the value is an IP address string first.
+func isIPCheck(path Path, v interface{}) (ip net.IP, errorResults *Results) {
+	strV, errorResults := isStrCheck(path, v)
+	if errorResults != nil {
+		return nil, errorResults
+	}
+
+	ip = net.ParseIP(strV)
+	if ip == nil {
+		return nil, SimpleResult(path, false, "String '%s' is not an IP address", truncateForMessage(strV))
+	}
+
+	return ip, nil
+}
+
+// IsIP tests that the value is a string holding an IPv4 or IPv6 address.
+var IsIP = Is("is an IP address", func(path Path, v interface{}) *Results {
+	if _, errorResults := isIPCheck(path, v); errorResults != nil {
+		return errorResults
+	}
+
+	return ValidResult(path)
+})
+
+// IsIPv4 tests that the value is a string holding an IPv4 address in dotted decimal form.
+var IsIPv4 = Is("is an IPv4 address", func(path Path, v interface{}) *Results {
+	ip, errorResults := isIPCheck(path, v)
+	if errorResults != nil {
+		return errorResults
+	}
+
+	// IPv4-mapped IPv6 addresses such as ::ffff:1.2.3.4 also have a To4 form, so check for a colon too
+	if ip.To4() == nil || strings.Contains(v.(string), ":") {
+		return SimpleResult(path, false, "IP address '%s' is not an IPv4 address", v)
+	}
+
+	return ValidResult(path)
+})
+
+// IsIPv6 tests that the value is a string holding an IPv6 address.
+var IsIPv6 = Is("is an IPv6 address", func(path Path, v interface{}) *Results {
+	_, errorResults := isIPCheck(path, v)
+	if errorResults != nil {
+		return errorResults
+	}
+
+	if !strings.Contains(v.(string), ":") {
+		return SimpleResult(path, false, "IP address '%s' is not an IPv6 address", v)
+	}
+
+	return ValidResult(path)
+})
+
+// IsCIDR tests that the value is a string holding an IP network in CIDR notation, such as 10.0.0.0/8.
+var IsCIDR = Is("is a CIDR", func(path Path, v interface{}) *Results {
+	strV, errorResults := isStrCheck(path, v)
+	if errorResults != nil {
+		return errorResults
+	}
+
+	if _, _, err := net.ParseCIDR(strV); err != nil {
+		return SimpleResult(path, false, "String '%s' is not a CIDR: %v", truncateForMessage(strV), err)
+	}
+
+	return ValidResult(path)
+})
+
+// IsBase64Encoding tests that the value is a string that can be decoded with the given encoding, such as
+// base64.URLEncoding or base64.RawStdEncoding.
+func IsBase64Encoding(enc *base64.Encoding) IsDef {
+	return Is("is base64", func(path Path, v interface{}) *Results {
+		strV, errorResults := isStrCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		if _, err := enc.DecodeString(strV); err != nil {
+			return SimpleResult(path, false, "could not decode base64: %v", err)
+		}
+
+		return ValidResult(path)
+	})
+}
+
+// IsBase64 tests that the value is a string encoded with standard, padded, base64 encoding.
+// Use IsBase64Encoding for other variants.
+var IsBase64 = IsBase64Encoding(base64.StdEncoding)
+
+// IsHex tests that the value is a string of hex encoded bytes, with an even number of digits.
+var IsHex = Is("is hex", func(path Path, v interface{}) *Results {
+	strV, errorResults := isStrCheck(path, v)
+	if errorResults != nil {
+		return errorResults
+	}
+
+	if _, err := hex.DecodeString(strV); err != nil {
+		return SimpleResult(path, false, "could not decode hex: %v", err)
+	}
+
+	return ValidResult(path)
+})
+
+// IsJSONString tests that the value is a string containing valid JSON.
+var IsJSONString = Is("is a JSON string", func(path Path, v interface{}) *Results {
+	strV, errorResults := isStrCheck(path, v)
+	if errorResults != nil {
+		return errorResults
+	}
+
+	if !json.Valid([]byte(strV)) {
+		return SimpleResult(path, false, "String '%s' is not valid JSON", truncateForMessage(strV))
+	}
+
+	return ValidResult(path)
+})
+
+// IsJSONStringMatching tests that the value is a string containing JSON, which when decoded is valid
+// according to the given Validator. Results from the inner Validator are reported under the path of
+// the string, so a failure in an embedded {"a": ...} object under key "blob" is reported at "blob.a". Their
+// messages are prefixed with "embedded JSON: ", while a string that can't be parsed fails with "could not parse
+// embedded JSON", so both are told apart from failures of the fields around the string.
+func IsJSONStringMatching(inner Validator) IsDef {
+	return Is("is a JSON string matching", func(path Path, v interface{}) *Results {
+		strV, errorResults := isStrCheck(path, v)
+		if errorResults != nil {
+			return errorResults
+		}
+
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(strV), &decoded); err != nil {
+			return SimpleResult(path, false, "could not parse embedded JSON: %v", err)
+		}
+
+		results := NewResults()
+		results.MergeUnderPrefix(path, inner(decoded).withMessagePrefix("embedded JSON: "))
+		return results
+	})
 }
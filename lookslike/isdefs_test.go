@@ -18,11 +18,19 @@
 package lookslike
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"math"
+	"reflect"
 	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 )
 
 func assertIsDefValid(t *testing.T, id IsDef, value interface{}) *Results {
@@ -76,6 +84,201 @@ func TestIsArrayOf(t *testing.T) {
 	assert.Contains(t, badFields, "p.[0].foo")
 }
 
+func TestIsSliceContainingAll(t *testing.T) {
+	id := IsSliceContainingAll(IsEqual("b"), IsString, IsNumeric)
+
+	assertIsDefValid(t, id, []interface{}{1, "a", "b"})
+	assertIsDefValid(t, id, []interface{}{"b", 2, "a", "extra"})
+	// Every def needs its own element, so one string can't satisfy both IsString and IsEqual("b")
+	assertIsDefInvalid(t, id, []interface{}{"b", 1})
+	assertIsDefInvalid(t, id, []interface{}{})
+	assertIsDefInvalid(t, id, "not a slice")
+
+	res := id.Check(MustParsePath("p"), []interface{}{"b", 1}, true)
+	assert.Contains(t, res.Fields["p"][0].Message, `1 of 3 definitions: []string{"is a string"}`)
+
+	// The matching is maximal even when the first element matches several defs
+	assertIsDefValid(t, IsSliceContainingAll(IsString, IsEqual("b")), []string{"b", "a"})
+
+	// With Strict unmatched elements are unexpected
+	validator := Strict(MustCompile(Map{"items": IsSliceContainingAll(IsEqual(2), IsEqual(1))}))
+	assert.True(t, validator(Map{"items": []interface{}{1, 2}}).Valid)
+	res = validator(Map{"items": []interface{}{1, 2, 3}})
+	assert.False(t, res.Valid)
+	assert.Equal(t, []ValueResult{StrictFailureVR}, res.DetailedErrors().Fields["items.[2]"])
+}
+
+func TestIsElementWhere(t *testing.T) {
+	id := IsElementWhere(
+		Ref(MustCompile(Map{"id": "b"})),
+		Ref(MustCompile(Map{"status": "ok"})),
+	)
+
+	assertIsDefValid(t, id, []interface{}{Map{"id": "a", "status": "failed"}, Map{"id": "b", "status": "ok"}})
+	assertIsDefInvalid(t, id, []interface{}{Map{"id": "a", "status": "ok"}, Map{"id": "b", "status": "failed"}})
+
+	// Every matching element is checked
+	assertIsDefInvalid(t, id, []interface{}{Map{"id": "b", "status": "ok"}, Map{"id": "b", "status": "failed"}})
+
+	res := id.Check(MustParsePath("p"), []interface{}{Map{"id": "a"}}, true)
+	assert.False(t, res.Valid)
+	assert.Equal(t, "no element of 1 matched ref", res.Fields["p"][0].Message)
+
+	res = id.Check(MustParsePath("p"), []interface{}{Map{"id": "a"}, Map{"id": "b", "status": "failed"}}, true)
+	assert.False(t, res.Fields["p.[1].status"][0].Valid)
+
+	assertIsDefInvalid(t, id, "not a slice")
+}
+
+func TestIsSliceOf(t *testing.T) {
+	id := IsSliceOf(IsString)
+
+	goodRes := assertIsDefValid(t, id, []string{"a", "b"})
+	assert.Len(t, goodRes.Fields, 2)
+	assert.Contains(t, goodRes.Fields, "p.[0]")
+	assert.Contains(t, goodRes.Fields, "p.[1]")
+
+	assertIsDefValid(t, id, []interface{}{"a", "b"})
+	assertIsDefValid(t, id, [2]string{"a", "b"})
+	assertIsDefValid(t, id, []string{})
+
+	badRes := assertIsDefInvalid(t, id, []interface{}{"a", 1, "c"})
+	assert.True(t, badRes.Fields["p.[0]"][0].Valid)
+	assert.False(t, badRes.Fields["p.[1]"][0].Valid)
+	assert.True(t, badRes.Fields["p.[2]"][0].Valid)
+
+	assertIsDefInvalid(t, id, "notaslice")
+	assertIsDefInvalid(t, id, nil)
+
+	res := MustCompile(Map{"tags": IsSliceOf(IsStringMatchingStr(`^[a-z]+$`))})(Map{"tags": []string{"a", "B"}})
+	assert.False(t, res.Valid)
+	assert.False(t, res.Fields["tags.[1]"][0].Valid)
+}
+
+func TestIsSliceUnique(t *testing.T) {
+	id := IsSliceUnique
+
+	assertIsDefValid(t, id, []int{1, 2, 3})
+	assertIsDefValid(t, id, []string{})
+	assertIsDefValid(t, id, []Map{{"a": 1}, {"a": 2}})
+	assertIsDefInvalid(t, id, []Map{{"a": 1}, {"a": 1}})
+	assertIsDefInvalid(t, id, "abc")
+	assertIsDefInvalid(t, id, nil)
+
+	res := assertIsDefInvalid(t, id, []string{"a", "b", "c", "b"})
+	assert.Equal(t, `Value "b" is duplicated at indices 1 and 3`, res.Fields["p"][0].Message)
+}
+
+func TestIsSorted(t *testing.T) {
+	assertIsDefValid(t, IsSorted, []int{1, 2, 2, 3})
+	assertIsDefValid(t, IsSorted, []interface{}{1, 2.5, uint(3)})
+	assertIsDefValid(t, IsSorted, []string{"a", "b", "c"})
+	assertIsDefValid(t, IsSorted, []string{})
+	assertIsDefValid(t, IsSorted, []Map{{}})
+	assertIsDefInvalid(t, IsSorted, []interface{}{1, "a"})
+	assertIsDefInvalid(t, IsSorted, "abc")
+
+	res := assertIsDefInvalid(t, IsSorted, []int{1, 3, 2})
+	assert.Equal(t, "Index 1 (3) is out of order with index 2 (2)", res.Fields["p"][0].Message)
+}
+
+func TestIsSortedBy(t *testing.T) {
+	byLen := IsSortedBy(func(a, b interface{}) bool {
+		return len(a.(string)) < len(b.(string))
+	})
+
+	assertIsDefValid(t, byLen, []string{"c", "bb", "aaa"})
+	assertIsDefValid(t, byLen, []string{"c"})
+	assertIsDefInvalid(t, byLen, []string{"aaa", "bb"})
+	assertIsDefInvalid(t, byLen, nil)
+}
+
+func TestIsSubsetOf(t *testing.T) {
+	id := IsSubsetOf(Slice{"a", "b", "c"})
+
+	assertIsDefValid(t, id, []string{"c", "a"})
+	assertIsDefValid(t, id, []string{"a", "a"})
+	assertIsDefValid(t, id, []string{})
+	assertIsDefInvalid(t, id, "a")
+
+	res := assertIsDefInvalid(t, id, []string{"a", "d", "e"})
+	assert.Contains(t, res.Fields["p"][0].Message, `"d", "e"`)
+}
+
+func TestIsSupersetOf(t *testing.T) {
+	id := IsSupersetOf(Slice{"a", "b"})
+
+	assertIsDefValid(t, id, []string{"b", "c", "a"})
+	assertIsDefValid(t, id, []interface{}{"a", 1, "b"})
+	assertIsDefInvalid(t, id, nil)
+
+	res := assertIsDefInvalid(t, id, []string{"a", "c"})
+	assert.Contains(t, res.Fields["p"][0].Message, `"b"`)
+	assert.NotContains(t, res.Fields["p"][0].Message, `"a"`)
+}
+
+func TestIsMapWithKeys(t *testing.T) {
+	id := IsMapWithKeys("a", "b")
+
+	assertIsDefValid(t, id, Map{"a": 1, "b": nil})
+	assertIsDefValid(t, id, map[string]string{"a": "", "b": "", "c": ""})
+	assertIsDefInvalid(t, id, map[int]string{1: ""})
+	assertIsDefInvalid(t, id, "a")
+	assertIsDefInvalid(t, id, nil)
+
+	res := assertIsDefInvalid(t, id, Map{"a": 1, "c": 2})
+	assert.Equal(t, `Map is missing required keys []string{"b"}`, res.Fields["p"][0].Message)
+}
+
+func TestIsMapOf(t *testing.T) {
+	id := IsMapOf(IsString)
+	assert.Equal(t, "map of "+IsString.Name, id.Name)
+
+	goodRes := assertIsDefValid(t, id, map[string]string{"a": "x", "b": "y"})
+	assert.Len(t, goodRes.Fields, 2)
+	assert.Contains(t, goodRes.Fields, "p.a")
+	assert.Contains(t, goodRes.Fields, "p.b")
+
+	assertIsDefValid(t, id, Map{})
+	assertIsDefValid(t, id, map[string]int{})
+
+	badRes := assertIsDefInvalid(t, id, Map{"a": "x", "b": 1})
+	assert.True(t, badRes.Fields["p.a"][0].Valid)
+	assert.False(t, badRes.Fields["p.b"][0].Valid)
+
+	assertIsDefInvalid(t, id, map[int]string{1: "x"})
+	assertIsDefInvalid(t, id, "notamap")
+	assertIsDefInvalid(t, id, nil)
+
+	users := MustCompile(Map{"users": IsMapOf(Ref(MustCompile(Map{"name": IsString})))})
+	res := Strict(users)(Map{"users": Map{"u1": Map{"name": "a"}, "u2": Map{"name": 2, "extra": true}}})
+	assert.False(t, res.Valid)
+	assert.True(t, res.Fields["users.u1.name"][0].Valid)
+	assert.False(t, res.Fields["users.u2.name"][0].Valid)
+	assert.Equal(t, StrictFailureVR, res.Fields["users.u2.extra"][0])
+}
+
+func TestIsMapWithKeysMatching(t *testing.T) {
+	id := IsMapWithKeysMatching(IsStringMatchingStr(`^[a-z_]+$`))
+
+	assertIsDefValid(t, id, Map{"snake_case": 1, "lower": nil})
+	assertIsDefValid(t, id, map[string]int{"a": 1})
+	assertIsDefValid(t, id, Map{})
+	assertIsDefInvalid(t, id, map[int]string{1: ""})
+	assertIsDefInvalid(t, id, "a")
+
+	res := assertIsDefInvalid(t, id, Map{"ok": 1, "camelCase": 2, "kebab-case": 3})
+	var failures []string
+	for _, vr := range res.Fields["p"] {
+		if !vr.Valid {
+			failures = append(failures, vr.Message)
+		}
+	}
+	require.Len(t, failures, 2)
+	assert.True(t, strings.HasPrefix(failures[0], "key 'camelCase': "), failures[0])
+	assert.True(t, strings.HasPrefix(failures[1], "key 'kebab-case': "), failures[1])
+}
+
 func TestIsAny(t *testing.T) {
 	id := IsAny(IsEqual("foo"), IsEqual("bar"))
 
@@ -84,6 +287,108 @@ func TestIsAny(t *testing.T) {
 	assertIsDefInvalid(t, id, "basta")
 }
 
+func TestAllOf(t *testing.T) {
+	id := AllOf(IsString, IsStringMatchingStr(`^f`), IsStringContaining("oo"))
+
+	assertIsDefValid(t, id, "foo")
+	assertIsDefValid(t, id, "food")
+	assertIsDefInvalid(t, id, 123)
+
+	res := assertIsDefInvalid(t, id, "fab")
+	msg := res.Fields["p"][0].Message
+	assert.Contains(t, msg, "is string containing")
+	assert.NotContains(t, msg, "is string matching regexp")
+
+	assertIsDefValid(t, AllOf(), "anything")
+}
+
+func TestIsOneOf(t *testing.T) {
+	id := IsOneOf("active", "inactive", 3, true)
+
+	assertIsDefValid(t, id, "active")
+	assertIsDefValid(t, id, "inactive")
+	assertIsDefValid(t, id, 3)
+	assertIsDefValid(t, id, true)
+	assertIsDefInvalid(t, id, "pending")
+	assertIsDefInvalid(t, id, false)
+	assertIsDefInvalid(t, id, nil)
+
+	assertIsDefValid(t, IsOneOf("a", nil), nil)
+
+	res := MustCompile(Map{"status": Optional(id)})(Map{})
+	assert.True(t, res.Valid)
+}
+
+type testStatus string
+
+const (
+	testStatusActive   testStatus = "active"
+	testStatusInactive testStatus = "inactive"
+)
+
+type testLevel int
+
+func (l testLevel) String() string {
+	return [...]string{"debug", "info", "error"}[l]
+}
+
+func TestIsEnum(t *testing.T) {
+	statuses := IsEnum([]testStatus{testStatusActive, testStatusInactive})
+	assertIsDefValid(t, statuses, testStatusActive)
+	assertIsDefValid(t, statuses, "inactive")
+	assertIsDefInvalid(t, statuses, nil)
+	assertIsDefInvalid(t, statuses, 1)
+
+	res := assertIsDefInvalid(t, statuses, "pending")
+	assert.Equal(t, "Value 'pending' is not a valid lookslike.testStatus, expected one of: active, inactive", res.Fields["p"][0].Message)
+
+	// Strings decoded from JSON match, but only after being coerced to the enum type
+	res = WithCoercionWarnings(MustCompile(Map{"status": statuses}))(Map{"status": "active"})
+	assert.True(t, res.Valid)
+	assert.Equal(t, []string{"passed only after coercing string(active) to lookslike.testStatus"}, res.Warnings()["status"])
+
+	levels := IsEnum([]testLevel{0, 1, 2})
+	assertIsDefValid(t, levels, testLevel(1))
+	assertIsDefValid(t, levels, 2.0)
+	assertIsDefInvalid(t, levels, 1.5)
+	assertIsDefInvalid(t, levels, "info")
+
+	res = assertIsDefInvalid(t, levels, 3)
+	assert.Equal(t, "Value '3' is not a valid lookslike.testLevel, expected one of: debug, info, error", res.Fields["p"][0].Message)
+
+	assert.Panics(t, func() { IsEnum(testStatusActive) })
+}
+
+func TestIsAnyMessageWithPercent(t *testing.T) {
+	res := assertIsDefInvalid(t, IsAny(IsEqual("100%"), IsEqual(5)), "50%d")
+	msg := res.Fields["p"][0].Message
+	assert.NotContains(t, msg, "%!")
+	assert.Contains(t, msg, `actual value was "50%d"`)
+	assert.Contains(t, msg, "actual(string(50%d)) != expected(string(100%))")
+}
+
+func TestAnyOf(t *testing.T) {
+	id := AnyOf(IsString, IsGTE(0))
+
+	assertIsDefValid(t, id, "foo")
+	assertIsDefValid(t, id, 5)
+	assertIsDefInvalid(t, id, -5)
+
+	res := assertIsDefInvalid(t, id, true)
+	msg := res.Fields["p"][0].Message
+	assert.Contains(t, msg, "is a string: ")
+	assert.Contains(t, msg, "greater than or equal to: ")
+
+	// Checking stops at the first match
+	calls := 0
+	counter := Is("counter", func(path Path, v interface{}) *Results {
+		calls++
+		return ValidResult(path)
+	})
+	assertIsDefValid(t, AnyOf(IsString, counter), "foo")
+	assert.Equal(t, 0, calls)
+}
+
 func TestIsEqual(t *testing.T) {
 	id := IsEqual("foo")
 
@@ -91,6 +396,98 @@ func TestIsEqual(t *testing.T) {
 	assertIsDefInvalid(t, id, "bar")
 }
 
+func TestIsEqualIgnoring(t *testing.T) {
+	expected := Map{
+		"id":      "abc",
+		"name":    "widget",
+		"created": "2020-01-01",
+		"parts":   []interface{}{Map{"id": 1, "n": "a"}, Map{"id": 2, "n": "b"}},
+	}
+	id := IsEqualIgnoring(expected, "id", "created", "parts.[*].id")
+
+	assertIsDefValid(t, id, Map{
+		"id":      "xyz",
+		"name":    "widget",
+		"created": "2021-05-05",
+		"parts":   []interface{}{Map{"id": 7, "n": "a"}, Map{"id": 8, "n": "b"}},
+	})
+	// Ignored keys may be missing altogether
+	assertIsDefValid(t, id, Map{
+		"name":  "widget",
+		"parts": []interface{}{Map{"n": "a"}, Map{"n": "b"}},
+	})
+
+	res := id.Check(MustParsePath("p"), Map{
+		"name":  "gadget",
+		"extra": true,
+		"parts": []interface{}{Map{"id": 7, "n": "z"}, Map{"id": 8}},
+	}, true)
+	assert.False(t, res.Valid)
+	errs := res.DetailedErrors().Fields
+	assert.Len(t, errs, 4)
+	assert.Contains(t, errs, "p.name")
+	assert.Equal(t, StrictFailureVR, errs["p.extra"][0])
+	assert.Contains(t, errs, "p.parts.[0].n")
+	assert.Equal(t, KeyMissingVR, errs["p.parts.[1].n"][0])
+
+	assertIsDefInvalid(t, id, "not a map")
+	assertIsDefInvalid(t, id, Map{"name": "widget", "parts": []interface{}{Map{"n": "a"}}})
+
+	assert.Panics(t, func() { IsEqualIgnoring(expected, "a..b") })
+}
+
+func TestIsEqualFunc(t *testing.T) {
+	trimmed := func(a, b interface{}) bool {
+		as, aOk := a.(string)
+		bs, bOk := b.(string)
+		return aOk && bOk && strings.TrimSpace(as) == strings.TrimSpace(bs)
+	}
+
+	assertIsDefValid(t, IsEqualFunc("foo", trimmed), "foo  ")
+	assertIsDefValid(t, IsEqualFunc(" foo", trimmed), "foo")
+	assertIsDefInvalid(t, IsEqualFunc("foo", trimmed), "bar")
+	assertIsDefInvalid(t, IsEqualFunc("1", trimmed), 1)
+
+	// The actual value is passed first
+	var gotA, gotB interface{}
+	IsEqualFunc("expected", func(a, b interface{}) bool {
+		gotA, gotB = a, b
+		return true
+	}).Check(MustParsePath("p"), "actual", true)
+	assert.Equal(t, "actual", gotA)
+	assert.Equal(t, "expected", gotB)
+}
+
+func TestIsEqualJSONNumber(t *testing.T) {
+	assertIsDefValid(t, IsEqual(5), json.Number("5"))
+	assertIsDefValid(t, IsEqual(5.0), json.Number("5"))
+	assertIsDefValid(t, IsEqual(int64(5)), json.Number("5.0"))
+	assertIsDefValid(t, IsEqual(json.Number("5")), 5)
+	assertIsDefInvalid(t, IsEqual(5), json.Number("6"))
+	assertIsDefInvalid(t, IsEqual(5), "5")
+
+	// Without a json.Number involved types must still match
+	assertIsDefValid(t, IsEqual(5), 5)
+	assertIsDefInvalid(t, IsEqual(5), 5.0)
+	assertIsDefInvalid(t, IsEqual(5), int64(5))
+}
+
+func TestIsNumEqual(t *testing.T) {
+	id := IsNumEqual(5)
+
+	assertIsDefValid(t, id, 5)
+	assertIsDefValid(t, id, 5.0)
+	assertIsDefValid(t, id, uint8(5))
+	assertIsDefValid(t, id, json.Number("5"))
+	assertIsDefInvalid(t, id, 5.5)
+	assertIsDefInvalid(t, id, "5")
+	assertIsDefInvalid(t, id, nil)
+
+	// Large integers are compared exactly
+	assertIsDefValid(t, IsNumEqual(int64(9007199254740993)), json.Number("9007199254740993"))
+	assertIsDefInvalid(t, IsNumEqual(int64(9007199254740993)), json.Number("9007199254740992"))
+}
+
 func TestRegisteredIsEqual(t *testing.T) {
 	// Time equality comes from a registered function
 	// so this is a quick way to test registered functions
@@ -107,6 +504,33 @@ func TestIsString(t *testing.T) {
 	assertIsDefInvalid(t, IsString, 123)
 }
 
+func TestIsBool(t *testing.T) {
+	assertIsDefValid(t, IsBool, true)
+	assertIsDefValid(t, IsBool, false)
+	assertIsDefInvalid(t, IsBool, "true")
+	assertIsDefInvalid(t, IsBool, 1)
+
+	res := assertIsDefInvalid(t, IsBool, nil)
+	assert.Contains(t, res.Fields["p"][0].Message, "<nil>")
+}
+
+func TestIsNumeric(t *testing.T) {
+	assertIsDefValid(t, IsNumeric, 1)
+	assertIsDefValid(t, IsNumeric, uint8(1))
+	assertIsDefValid(t, IsNumeric, int64(-1))
+	assertIsDefValid(t, IsNumeric, float32(1.5))
+	assertIsDefValid(t, IsNumeric, json.Number("1.5"))
+	assertIsDefInvalid(t, IsNumeric, json.Number("potato"))
+	assertIsDefInvalid(t, IsNumeric, "1.5")
+	assertIsDefInvalid(t, IsNumeric, true)
+	assertIsDefInvalid(t, IsNumeric, nil)
+
+	res := assertIsDefInvalid(t, IsNumeric, "1.5")
+	assert.Contains(t, res.Fields["p"][0].Message, "string")
+
+	assertIsDefValid(t, IsGT(1), json.Number("2"))
+}
+
 func TestIsNonEmptyString(t *testing.T) {
 	assertIsDefValid(t, IsNonEmptyString, "abc")
 	assertIsDefValid(t, IsNonEmptyString, "a")
@@ -122,6 +546,56 @@ func TestIsStringMatching(t *testing.T) {
 	assertIsDefInvalid(t, IsString, 123)
 }
 
+func TestIsStringMatchingStr(t *testing.T) {
+	id := IsStringMatchingStr(`^f`)
+
+	assertIsDefValid(t, id, "fall")
+	assertIsDefInvalid(t, id, "potato")
+	assertIsDefInvalid(t, id, 123)
+	assertIsDefInvalid(t, id, nil)
+
+	long := strings.Repeat("x", 1000)
+	res := assertIsDefInvalid(t, id, long)
+	msg := res.Fields["p"][0].Message
+	assert.Contains(t, msg, "^f")
+	assert.NotContains(t, msg, long)
+
+	// Multi-byte characters straddling the limit aren't split
+	res = assertIsDefInvalid(t, id, "x"+strings.Repeat("é", 100))
+	msg = res.Fields["p"][0].Message
+	assert.True(t, utf8.ValidString(msg), msg)
+	assert.Contains(t, msg, "'x"+strings.Repeat("é", (maxMessageValueLen-1)/2)+"...'")
+}
+
+func TestIsStringCaptures(t *testing.T) {
+	atLeast2 := IsParseableAs(func(s string) error {
+		if n, err := strconv.Atoi(s); err != nil || n < 2 {
+			return fmt.Errorf("expected an integer >= 2")
+		}
+		return nil
+	})
+	id := IsStringCaptures(regexp.MustCompile(`^v(\d+)\.(\d+)(-\w+)?$`), map[int]IsDef{
+		1: atLeast2,
+		2: IsStringMatchingStr(`^\d$`),
+		3: Optional(IsEqual("-beta")),
+	})
+
+	assertIsDefValid(t, id, "v2.0")
+	assertIsDefValid(t, id, "v10.3-beta")
+	assertIsDefInvalid(t, id, "v2.0-alpha")
+	assertIsDefInvalid(t, id, "2.0")
+	assertIsDefInvalid(t, id, 2.0)
+
+	res := assertIsDefInvalid(t, id, "v1.10")
+	require.Len(t, res.Fields["p"], 3)
+	assert.Equal(t, "capture group 1: could not parse '1': expected an integer >= 2", res.Fields["p"][1].Message)
+	assert.True(t, strings.HasPrefix(res.Fields["p"][2].Message, "capture group 2: "))
+
+	assert.Panics(t, func() {
+		IsStringCaptures(regexp.MustCompile(`(a)`), map[int]IsDef{2: IsString})
+	})
+}
+
 func TestIsStringContaining(t *testing.T) {
 	id := IsStringContaining("foo")
 
@@ -129,13 +603,257 @@ func TestIsStringContaining(t *testing.T) {
 	assertIsDefValid(t, id, "a foo b")
 	assertIsDefInvalid(t, id, "a bar b")
 	assertIsDefInvalid(t, IsString, 123)
+
+	assertIsDefValid(t, IsStringContaining(""), "anything")
+	assertIsDefValid(t, IsStringContaining(""), "")
+	assertIsDefInvalid(t, IsStringContaining(""), 123)
+}
+
+func TestIsStringContainingFold(t *testing.T) {
+	id := IsStringContainingFold("Foo")
+
+	assertIsDefValid(t, id, "foo")
+	assertIsDefValid(t, id, "a FOO b")
+	assertIsDefInvalid(t, id, "a bar b")
+	assertIsDefInvalid(t, id, 123)
+}
+
+func TestIsEqualFold(t *testing.T) {
+	id := IsEqualFold("Active")
+
+	assertIsDefValid(t, id, "active")
+	assertIsDefValid(t, id, "ACTIVE")
+	assertIsDefInvalid(t, id, "inactive")
+	assertIsDefInvalid(t, id, nil)
+
+	res := id.Check(MustParsePath("p"), 1, true)
+	assert.Contains(t, res.Fields["p"][0].Message, "it is a int")
+
+	validator := MustCompile(Map{"status": IsEqualFold("ok")})
+	assert.True(t, validator(Map{"status": "OK"}).Valid)
 }
 
 func TestIsDuration(t *testing.T) {
 	id := IsDuration
 
 	assertIsDefValid(t, id, time.Duration(1))
+	assertIsDefValid(t, id, "30s")
+	assertIsDefValid(t, id, "1h2m")
 	assertIsDefInvalid(t, id, "foo")
+	assertIsDefInvalid(t, id, 30)
+	assertIsDefInvalid(t, id, nil)
+}
+
+func TestIsDurationGTE(t *testing.T) {
+	id := IsDurationGTE(time.Second)
+
+	assertIsDefValid(t, id, time.Second)
+	assertIsDefValid(t, id, "2s")
+	assertIsDefInvalid(t, id, time.Millisecond)
+
+	res := assertIsDefInvalid(t, id, "10ms")
+	assert.Contains(t, res.Fields["p"][0].Message, "too small")
+	res = assertIsDefInvalid(t, id, "potato")
+	assert.Contains(t, res.Fields["p"][0].Message, "not a duration")
+}
+
+func TestIsRFC3339(t *testing.T) {
+	assertIsDefValid(t, IsRFC3339, "2019-03-12T10:15:00Z")
+	assertIsDefValid(t, IsRFC3339, "2019-03-12T10:15:00+01:00")
+	assertIsDefValid(t, IsRFC3339, time.Now())
+	assertIsDefInvalid(t, IsRFC3339, "2019-03-12")
+	assertIsDefInvalid(t, IsRFC3339, 1552385700)
+
+	res := assertIsDefInvalid(t, IsRFC3339, "yesterday")
+	assert.Contains(t, res.Fields["p"][0].Message, time.RFC3339)
+}
+
+func TestIsTimeFormat(t *testing.T) {
+	id := IsTimeFormat("2006-01-02")
+
+	assertIsDefValid(t, id, "2019-03-12")
+	assertIsDefInvalid(t, id, "2019-03-12T10:15:00Z")
+	assertIsDefInvalid(t, id, nil)
+}
+
+func TestIsUUID(t *testing.T) {
+	assertIsDefValid(t, IsUUID, "123e4567-e89b-12d3-a456-426655440000")
+	assertIsDefValid(t, IsUUID, "123E4567-E89B-12D3-A456-426655440000")
+	assertIsDefInvalid(t, IsUUID, "123e4567e89b12d3a456426655440000")
+	assertIsDefInvalid(t, IsUUID, "123e4567-e89b-12d3-a456-42665544000g")
+	assertIsDefInvalid(t, IsUUID, 123)
+}
+
+func TestIsUUIDVersion(t *testing.T) {
+	id := IsUUIDVersion(4)
+
+	assertIsDefValid(t, id, "f47ac10b-58cc-4372-a567-0e02b2c3d479")
+	assertIsDefInvalid(t, id, "not-a-uuid")
+	assertIsDefInvalid(t, id, nil)
+
+	res := assertIsDefInvalid(t, id, "123e4567-e89b-12d3-a456-426655440000")
+	assert.Contains(t, res.Fields["p"][0].Message, "is version 1, expected version 4")
+}
+
+func TestIsParseableAs(t *testing.T) {
+	parseLevel := func(s string) error {
+		switch s {
+		case "debug", "info", "error":
+			return nil
+		default:
+			return fmt.Errorf("unknown level %q", s)
+		}
+	}
+	id := IsParseableAs(parseLevel)
+
+	assertIsDefValid(t, id, "info")
+	res := assertIsDefInvalid(t, id, "verbose")
+	assert.Equal(t, `could not parse 'verbose': unknown level "verbose"`, res.Fields["p"][0].Message)
+	long := strings.Repeat("x", 100)
+	res = assertIsDefInvalid(t, IsParseableAs(func(string) error { return fmt.Errorf("bad") }), long)
+	assert.Equal(t, "could not parse '"+long[:maxMessageValueLen]+"...': bad", res.Fields["p"][0].Message)
+	assertIsDefInvalid(t, id, 1)
+	assertIsDefInvalid(t, id, nil)
+}
+
+func TestIsNumericString(t *testing.T) {
+	assertIsDefValid(t, IsNumericString, "42")
+	assertIsDefValid(t, IsNumericString, "-1.5e3")
+	assertIsDefValid(t, IsNumericString, "0.25")
+	assertIsDefInvalid(t, IsNumericString, "")
+	assertIsDefInvalid(t, IsNumericString, " 42")
+	assertIsDefInvalid(t, IsNumericString, "NaN")
+	assertIsDefInvalid(t, IsNumericString, "Inf")
+	assertIsDefInvalid(t, IsNumericString, 42)
+	assertIsDefInvalid(t, IsNumericString, nil)
+}
+
+func TestIsNumericStringEqual(t *testing.T) {
+	id := IsNumericStringEqual(42)
+	assertIsDefValid(t, id, "42")
+	assertIsDefValid(t, id, "42.0")
+	assertIsDefValid(t, id, "4.2e1")
+
+	res := assertIsDefInvalid(t, id, 42)
+	assert.Equal(t, "Unable to convert '42' to string, it is a int", res.Fields["p"][0].Message)
+	res = assertIsDefInvalid(t, id, "forty-two")
+	assert.Equal(t, "String 'forty-two' is not parseable as a number", res.Fields["p"][0].Message)
+	res = assertIsDefInvalid(t, id, "41.5")
+	assert.Equal(t, "String '41.5' holds 41.5, expected 42", res.Fields["p"][0].Message)
+}
+
+func TestIsEmail(t *testing.T) {
+	assertIsDefValid(t, IsEmail, "user@example.net")
+	assertIsDefValid(t, IsEmail, "first.last+tag@sub.example.net")
+	assertIsDefInvalid(t, IsEmail, "User <user@example.net>")
+	assertIsDefInvalid(t, IsEmail, "user")
+	assertIsDefInvalid(t, IsEmail, "user@")
+	assertIsDefInvalid(t, IsEmail, "")
+	assertIsDefInvalid(t, IsEmail, 42)
+}
+
+func TestIsURL(t *testing.T) {
+	assertIsDefValid(t, IsURL, "https://example.net/hook?x=1")
+	assertIsDefValid(t, IsURL, "ftp://example.net")
+	assertIsDefInvalid(t, IsURL, "/relative/path")
+	assertIsDefInvalid(t, IsURL, "example.net")
+	assertIsDefInvalid(t, IsURL, "http://%zz")
+	assertIsDefInvalid(t, IsURL, 1)
+}
+
+func TestIsURLWithScheme(t *testing.T) {
+	id := IsURLWithScheme("https")
+
+	assertIsDefValid(t, id, "https://example.net")
+	assertIsDefValid(t, id, "HTTPS://example.net")
+	assertIsDefInvalid(t, id, "/nohost")
+
+	res := assertIsDefInvalid(t, id, "http://example.net")
+	assert.Contains(t, res.Fields["p"][0].Message, "not allowed")
+}
+
+func TestIsIP(t *testing.T) {
+	assertIsDefValid(t, IsIP, "10.0.0.1")
+	assertIsDefValid(t, IsIP, "::1")
+	assertIsDefInvalid(t, IsIP, "10.0.0.256")
+	assertIsDefInvalid(t, IsIP, "example.net")
+	assertIsDefInvalid(t, IsIP, 10)
+
+	assertIsDefValid(t, IsIPv4, "10.0.0.1")
+	assertIsDefInvalid(t, IsIPv4, "::1")
+	assertIsDefInvalid(t, IsIPv4, "::ffff:10.0.0.1")
+	assertIsDefInvalid(t, IsIPv4, "potato")
+
+	assertIsDefValid(t, IsIPv6, "::1")
+	assertIsDefValid(t, IsIPv6, "2001:db8::68")
+	assertIsDefValid(t, IsIPv6, "::ffff:10.0.0.1")
+	assertIsDefInvalid(t, IsIPv6, "10.0.0.1")
+	assertIsDefInvalid(t, IsIPv6, nil)
+}
+
+func TestIsCIDR(t *testing.T) {
+	assertIsDefValid(t, IsCIDR, "10.0.0.0/8")
+	assertIsDefValid(t, IsCIDR, "2001:db8::/32")
+	assertIsDefInvalid(t, IsCIDR, "10.0.0.1")
+	assertIsDefInvalid(t, IsCIDR, "10.0.0.0/33")
+	assertIsDefInvalid(t, IsCIDR, false)
+}
+
+func TestIsBase64(t *testing.T) {
+	assertIsDefValid(t, IsBase64, "aGVsbG8/Pw==")
+	assertIsDefValid(t, IsBase64, "")
+	assertIsDefInvalid(t, IsBase64, "aGVsbG8_Pw==")
+	assertIsDefInvalid(t, IsBase64, "aGVsbG8")
+	assertIsDefInvalid(t, IsBase64, []byte("aGVsbG8="))
+
+	res := assertIsDefInvalid(t, IsBase64, "!!!!")
+	assert.Contains(t, res.Fields["p"][0].Message, "illegal base64 data")
+
+	urlID := IsBase64Encoding(base64.URLEncoding)
+	assertIsDefValid(t, urlID, "aGVsbG8_Pw==")
+	assertIsDefInvalid(t, urlID, "aGVsbG8/Pw==")
+
+	assertIsDefValid(t, IsBase64Encoding(base64.RawStdEncoding), "aGVsbG8")
+}
+
+func TestIsHex(t *testing.T) {
+	assertIsDefValid(t, IsHex, "deadBEEF")
+	assertIsDefValid(t, IsHex, "")
+	assertIsDefInvalid(t, IsHex, "abc")
+	assertIsDefInvalid(t, IsHex, 0xff)
+
+	res := assertIsDefInvalid(t, IsHex, "zz")
+	assert.Contains(t, res.Fields["p"][0].Message, "invalid byte")
+}
+
+func TestIsJSONString(t *testing.T) {
+	assertIsDefValid(t, IsJSONString, `{"a": [1, 2]}`)
+	assertIsDefValid(t, IsJSONString, `"foo"`)
+	assertIsDefInvalid(t, IsJSONString, `{"a": `)
+	assertIsDefInvalid(t, IsJSONString, Map{})
+}
+
+func TestIsJSONStringMatching(t *testing.T) {
+	id := IsJSONStringMatching(MustCompile(Map{
+		"a":   IsNumeric,
+		"b.c": "d",
+	}))
+
+	goodRes := assertIsDefValid(t, id, `{"a": 1, "b": {"c": "d"}}`)
+	assert.Contains(t, goodRes.Fields, "p.a")
+	assert.Contains(t, goodRes.Fields, "p.b.c")
+
+	badRes := assertIsDefInvalid(t, id, `{"a": "1", "b": {"c": "d"}}`)
+	assert.False(t, badRes.Fields["p.a"][0].Valid)
+	assert.True(t, badRes.Fields["p.b.c"][0].Valid)
+	// Failures of the inner Validator are labelled as such, apart from parse errors
+	assert.True(t, strings.HasPrefix(badRes.Fields["p.a"][0].Message, "embedded JSON: "), badRes.Fields["p.a"][0].Message)
+	assert.NotContains(t, badRes.Fields["p.a"][0].Message, "could not parse")
+
+	parseRes := assertIsDefInvalid(t, id, `{"a": `)
+	assert.Contains(t, parseRes.Fields["p"][0].Message, "could not parse embedded JSON")
+
+	assertIsDefInvalid(t, id, 1)
 }
 
 func TestIsIntGt(t *testing.T) {
@@ -146,9 +864,318 @@ func TestIsIntGt(t *testing.T) {
 	assertIsDefInvalid(t, id, 99)
 }
 
+func TestNumericComparisons(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      IsDef
+		valid   []interface{}
+		invalid []interface{}
+	}{
+		{"IsGT", IsGT(10), []interface{}{11, int64(11), uint8(11), float32(10.5), 10.1}, []interface{}{10, 9, -11.0}},
+		{"IsGTE", IsGTE(10), []interface{}{10, uint(10), 10.0, 11}, []interface{}{9, 9.99}},
+		{"IsLT", IsLT(10), []interface{}{9, int8(-10), 9.99}, []interface{}{10, uint64(11)}},
+		{"IsLTE", IsLTE(10), []interface{}{10, 10.0, -3}, []interface{}{11, 10.01}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, v := range tt.valid {
+				assertIsDefValid(t, tt.id, v)
+			}
+			for _, v := range tt.invalid {
+				assertIsDefInvalid(t, tt.id, v)
+			}
+			assertIsDefInvalid(t, tt.id, "10")
+			assertIsDefInvalid(t, tt.id, nil)
+		})
+	}
+
+	res := assertIsDefInvalid(t, IsGT(10), 7)
+	assert.Equal(t, "expected value > 10, got 7", res.Fields["p"][0].Message)
+}
+
+func TestIsSign(t *testing.T) {
+	assertIsDefValid(t, IsPositive, 1)
+	assertIsDefValid(t, IsPositive, uint8(3))
+	assertIsDefValid(t, IsPositive, json.Number("0.5"))
+	assertIsDefInvalid(t, IsPositive, 0)
+	assertIsDefInvalid(t, IsPositive, math.NaN())
+
+	assertIsDefValid(t, IsNegative, int64(-1))
+	assertIsDefInvalid(t, IsNegative, 0)
+
+	assertIsDefValid(t, IsNonNegative, 0)
+	assertIsDefValid(t, IsNonNegative, float32(2.5))
+	assertIsDefInvalid(t, IsNonNegative, -0.1)
+
+	assertIsDefValid(t, IsNonPositive, 0)
+	assertIsDefValid(t, IsNonPositive, int8(-4))
+	assertIsDefInvalid(t, IsNonPositive, 1)
+
+	res := assertIsDefInvalid(t, IsPositive, "1")
+	assert.Equal(t, "Expected a numeric value, got '1' which is a string", res.Fields["p"][0].Message)
+	res = assertIsDefInvalid(t, IsNonNegative, -3)
+	assert.Equal(t, "expected value >= 0, got -3", res.Fields["p"][0].Message)
+}
+
+func TestIsInteger(t *testing.T) {
+	assertIsDefValid(t, IsInteger, 3)
+	assertIsDefValid(t, IsInteger, 3.0)
+	assertIsDefValid(t, IsInteger, -1e20)
+	assertIsDefValid(t, IsInteger, uint64(math.MaxUint64))
+	assertIsDefValid(t, IsInteger, json.Number("42"))
+	assertIsDefInvalid(t, IsInteger, math.Inf(1))
+	assertIsDefInvalid(t, IsInteger, math.NaN())
+	assertIsDefInvalid(t, IsInteger, "3")
+
+	res := assertIsDefInvalid(t, IsInteger, 3.5)
+	assert.Equal(t, "expected an integer, got 3.5", res.Fields["p"][0].Message)
+}
+
+func TestIsInt64(t *testing.T) {
+	assertIsDefValid(t, IsInt64, int64(math.MaxInt64))
+	assertIsDefValid(t, IsInt64, json.Number("9223372036854775807"))
+	assertIsDefValid(t, IsInt64, float64(math.MinInt64))
+	assertIsDefValid(t, IsInt64, 1e18)
+	assertIsDefInvalid(t, IsInt64, 0.5)
+	assertIsDefInvalid(t, IsInt64, float64(math.MaxInt64))
+
+	res := assertIsDefInvalid(t, IsInt64, uint64(math.MaxUint64))
+	assert.Equal(t, "expected an integer within the range of an int64, got 18446744073709551615", res.Fields["p"][0].Message)
+	res = assertIsDefInvalid(t, IsInt64, 1e20)
+	assert.Equal(t, "expected an integer within the range of an int64, got 1e+20", res.Fields["p"][0].Message)
+}
+
+func TestIsBetween(t *testing.T) {
+	id := IsBetween(0, 100)
+
+	assertIsDefValid(t, id, 0)
+	assertIsDefValid(t, id, 100)
+	assertIsDefValid(t, id, 55.5)
+	assertIsDefValid(t, id, uint16(42))
+	assertIsDefInvalid(t, id, -1)
+	assertIsDefInvalid(t, id, 100.01)
+	assertIsDefInvalid(t, id, "50")
+
+	res := assertIsDefInvalid(t, id, 101)
+	assert.Contains(t, res.Fields["p"][0].Message, "<= 100")
+	res = assertIsDefInvalid(t, id, -1)
+	assert.Contains(t, res.Fields["p"][0].Message, ">= 0")
+}
+
+func TestIsPercentage(t *testing.T) {
+	assertIsDefValid(t, IsPercentage, 0)
+	assertIsDefValid(t, IsPercentage, 100)
+	assertIsDefValid(t, IsPercentage, 99.9)
+	assertIsDefInvalid(t, IsPercentage, -0.1)
+	assertIsDefInvalid(t, IsPercentage, "50")
+
+	res := assertIsDefInvalid(t, IsPercentage, 101)
+	assert.Equal(t, "expected value <= 100 (upper bound of [0, 100]), got 101", res.Fields["p"][0].Message)
+}
+
+func TestIsRatio(t *testing.T) {
+	assertIsDefValid(t, IsRatio, 0)
+	assertIsDefValid(t, IsRatio, 1)
+	assertIsDefValid(t, IsRatio, float32(0.25))
+	assertIsDefInvalid(t, IsRatio, 1.01)
+	assertIsDefInvalid(t, IsRatio, nil)
+
+	res := assertIsDefInvalid(t, IsRatio, -0.5)
+	assert.Equal(t, "expected value >= 0 (lower bound of [0, 1]), got -0.5", res.Fields["p"][0].Message)
+}
+
+func TestIsBetweenExclusive(t *testing.T) {
+	id := IsBetweenExclusive(0, 100)
+
+	assertIsDefValid(t, id, 1)
+	assertIsDefValid(t, id, 99.9)
+	assertIsDefInvalid(t, id, 0)
+	assertIsDefInvalid(t, id, 100)
+	assertIsDefInvalid(t, id, nil)
+}
+
+func TestIsCloseTo(t *testing.T) {
+	id := IsCloseTo(1.0, 0.01)
+
+	assertIsDefValid(t, id, 1.0)
+	assertIsDefValid(t, id, 1.005)
+	assertIsDefValid(t, id, float32(0.995))
+	assertIsDefValid(t, id, 1)
+	assertIsDefInvalid(t, id, 1.1)
+	assertIsDefInvalid(t, id, "1.0")
+	assertIsDefInvalid(t, id, math.NaN())
+
+	res := assertIsDefInvalid(t, id, 2.0)
+	assert.Contains(t, res.Fields["p"][0].Message, "delta 1")
+
+	assertIsDefInvalid(t, IsCloseTo(math.NaN(), 1), 1.0)
+	assertIsDefValid(t, IsCloseTo(math.Inf(1), 1), math.Inf(1))
+	assertIsDefInvalid(t, IsCloseTo(math.Inf(1), 1), math.Inf(-1))
+	assertIsDefInvalid(t, IsCloseTo(math.Inf(1), math.Inf(1)), 5.0)
+	assertIsDefInvalid(t, IsCloseTo(5.0, 1), math.Inf(1))
+}
+
+func TestIsLength(t *testing.T) {
+	id := IsLength(2)
+
+	assertIsDefValid(t, id, "ab")
+	assertIsDefValid(t, id, []int{1, 2})
+	assertIsDefValid(t, id, [2]string{})
+	assertIsDefValid(t, id, Map{"a": 1, "b": 2})
+	assertIsDefInvalid(t, id, "abc")
+	assertIsDefInvalid(t, id, []int{1})
+	assertIsDefInvalid(t, id, 2)
+	assertIsDefInvalid(t, id, nil)
+
+	res := assertIsDefInvalid(t, id, []int{1, 2, 3})
+	assert.Equal(t, "expected length 2, got 3", res.Fields["p"][0].Message)
+
+	// nil slices and maps have a length of zero
+	assertIsDefValid(t, IsLength(0), []int(nil))
+	assertIsDefValid(t, IsLength(0), map[string]int(nil))
+}
+
+func TestIsLengthBounds(t *testing.T) {
+	assertIsDefValid(t, IsLengthGTE(2), []int{1, 2})
+	assertIsDefValid(t, IsLengthGTE(2), "abc")
+	assertIsDefInvalid(t, IsLengthGTE(2), []int{1})
+	assertIsDefInvalid(t, IsLengthGTE(2), 5)
+
+	assertIsDefValid(t, IsLengthLTE(2), []int{1, 2})
+	assertIsDefValid(t, IsLengthLTE(2), "")
+	assertIsDefInvalid(t, IsLengthLTE(2), "abc")
+	assertIsDefInvalid(t, IsLengthLTE(2), nil)
+}
+
+func TestIsEmpty(t *testing.T) {
+	assertIsDefValid(t, IsEmpty, "")
+	assertIsDefValid(t, IsEmpty, []string{})
+	assertIsDefValid(t, IsEmpty, Map{})
+	assertIsDefValid(t, IsEmpty, nil)
+	assertIsDefValid(t, IsEmpty, []int(nil))
+	assertIsDefInvalid(t, IsEmpty, "a")
+	assertIsDefInvalid(t, IsEmpty, 0)
+
+	res := assertIsDefInvalid(t, IsEmpty, []int{1, 2})
+	assert.Equal(t, "expected slice to be empty, got length 2", res.Fields["p"][0].Message)
+}
+
+func TestIsNonEmpty(t *testing.T) {
+	assertIsDefValid(t, IsNonEmpty, "a")
+	assertIsDefValid(t, IsNonEmpty, []string{"a"})
+	assertIsDefValid(t, IsNonEmpty, Map{"a": 1})
+	assertIsDefInvalid(t, IsNonEmpty, "")
+	assertIsDefInvalid(t, IsNonEmpty, Map{})
+	assertIsDefInvalid(t, IsNonEmpty, nil)
+	assertIsDefInvalid(t, IsNonEmpty, 1)
+}
+
+func TestIsKind(t *testing.T) {
+	id := IsKind(reflect.Float64)
+
+	assertIsDefValid(t, id, 1.5)
+	assertIsDefInvalid(t, id, "1.5")
+	assertIsDefInvalid(t, id, float32(1.5))
+	assertIsDefInvalid(t, id, nil)
+
+	res := assertIsDefInvalid(t, id, "1.5")
+	assert.Equal(t, "expected kind float64, got string (string)", res.Fields["p"][0].Message)
+
+	assertIsDefValid(t, IsKind(reflect.Map), Map{})
+	assertIsDefValid(t, IsKind(reflect.Map), map[string]int{})
+}
+
+func TestIsType(t *testing.T) {
+	id := IsType(Map{})
+
+	assertIsDefValid(t, id, Map{"a": 1})
+	assertIsDefInvalid(t, id, map[string]interface{}{})
+	assertIsDefInvalid(t, id, nil)
+
+	assertIsDefValid(t, IsType(time.Second), time.Duration(5))
+	assertIsDefInvalid(t, IsType(time.Second), int64(5))
+}
+
+func TestIf(t *testing.T) {
+	id := If(IsString, IsNonEmptyString, IsGT(0))
+
+	assertIsDefValid(t, id, "x")
+	assertIsDefValid(t, id, 3)
+
+	res := id.Check(MustParsePath("p"), "", true)
+	assert.False(t, res.Valid)
+	assert.Contains(t, res.Fields["p"][0].Message, "condition is a string matched, then: ")
+
+	res = id.Check(MustParsePath("p"), -1, true)
+	assert.False(t, res.Valid)
+	assert.Contains(t, res.Fields["p"][0].Message, "condition is a string did not match, else: ")
+}
+
+func TestCapture(t *testing.T) {
+	var start, end interface{}
+	validator := MustCompile(Map{
+		"start": Capture("start", &start),
+		"end":   Capture("end", &end),
+	})
+
+	res := validator(Map{"start": 1, "end": 5})
+	assert.True(t, res.Valid)
+	assert.Equal(t, 1, start)
+	assert.Equal(t, 5, end)
+
+	// Missing keys fail and leave the pointer untouched
+	res = validator(Map{"start": nil})
+	assert.False(t, res.Valid)
+	assert.Nil(t, start)
+	assert.Equal(t, 5, end)
+
+	var last interface{}
+	MustCompile(Map{"items.[*]": Capture("last item", &last)})(Map{"items": []interface{}{"a", "b", "c"}})
+	assert.Equal(t, "c", last)
+
+	assertIsDefValid(t, Optional(Capture("opt", &last)), nil)
+}
+
+func TestIsJSONNull(t *testing.T) {
+	assertIsDefValid(t, IsJSONNull, nil)
+	assertIsDefInvalid(t, IsJSONNull, (*string)(nil))
+	assertIsDefInvalid(t, IsJSONNull, []int(nil))
+	assertIsDefInvalid(t, IsJSONNull, "")
+	assertIsDefInvalid(t, IsJSONNull, 0)
+
+	var decoded Map
+	require.NoError(t, json.Unmarshal([]byte(`{"cleared": null, "kept": "x"}`), &decoded))
+
+	validator := Strict(MustCompile(Map{"cleared": IsJSONNull, "kept": IsString}))
+	assert.True(t, validator(decoded).Valid)
+	assert.Equal(t, KeyMissingVR, validator(Map{"kept": "x"}).Fields["cleared"][0])
+
+	res := validator(Map{"cleared": nil, "kept": "x", "other": nil})
+	assert.False(t, res.Valid)
+	assert.Equal(t, StrictFailureVR, res.Fields["other"][0])
+}
+
 func TestIsNil(t *testing.T) {
 	assertIsDefValid(t, IsNil, nil)
+	assertIsDefValid(t, IsNil, (*string)(nil))
+	assertIsDefValid(t, IsNil, []int(nil))
 	assertIsDefInvalid(t, IsNil, "foo")
+	assertIsDefInvalid(t, IsNil, 0)
+
+	// IsNil requires presence, Optional permits absence
+	assert.False(t, MustCompile(Map{"a": IsNil})(Map{}).Valid)
+	assert.True(t, MustCompile(Map{"a": IsNil})(Map{"a": nil}).Valid)
+	assert.True(t, MustCompile(Map{"a": Optional(IsNil)})(Map{}).Valid)
+}
+
+func TestIsNonNil(t *testing.T) {
+	assertIsDefValid(t, IsNonNil, "foo")
+	assertIsDefValid(t, IsNonNil, 0)
+	assertIsDefInvalid(t, IsNonNil, nil)
+	assertIsDefInvalid(t, IsNonNil, (*string)(nil))
+
+	assert.False(t, MustCompile(Map{"a": IsNonNil})(Map{}).Valid)
 }
 
 func TestIsUnique(t *testing.T) {
@@ -192,6 +1219,12 @@ func TestIsUnique(t *testing.T) {
 			Map{"a": 1, "b": 1},
 			true,
 		},
+		{
+			"IsUnique values that can't be map keys fail",
+			func() Validator { return MustCompile(Map{"a": IsUnique()}) },
+			Map{"a": []interface{}{1}},
+			false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
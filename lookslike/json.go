@@ -0,0 +1,138 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lookslike
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// JSONOption configures how ValidateJSON decodes its input.
+type JSONOption func(decoder *json.Decoder)
+
+// UseNumber makes ValidateJSON decode numbers as json.Number rather than float64, preserving the precision of
+// large integers. The numeric IsDefs, and IsEqual, all accept json.Number values.
+var UseNumber JSONOption = func(decoder *json.Decoder) {
+	decoder.UseNumber()
+}
+
+// ValidateJSON decodes a single JSON value from the given reader and runs the Validator against it, which
+// is convenient for validating request and response bodies. Errors decoding the JSON, including any data
+// following the value, are returned as the error rather than being recorded in the Results.
+func ValidateJSON(r io.Reader, v Validator, opts ...JSONOption) (*Results, error) {
+	actual, err := decodeSingleJSON(r, opts)
+	if err != nil {
+		return nil, err
+	}
+	return v(actual), nil
+}
+
+// decodeSingleJSON decodes a JSON value from the given reader, returning an error if any data follows it.
+func decodeSingleJSON(r io.Reader, opts []JSONOption) (interface{}, error) {
+	decoder := json.NewDecoder(r)
+	for _, opt := range opts {
+		opt(decoder)
+	}
+
+	var actual interface{}
+	if err := decoder.Decode(&actual); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+	return actual, nil
+}
+
+// ValidateJSONArrayStream validates each element of a top level JSON array read from the given reader, decoding
+// and validating one element at a time so that arbitrarily large arrays can be checked with bounded memory.
+// The Results for each element are passed to onResult along with the element's index, and validation stops
+// early, without reading the rest of the input, as soon as onResult returns false.
+//
+// Errors decoding the JSON, including input that is not an array or that has data following it, are returned
+// as the error. Elements validated before the error was encountered will already have been passed to onResult.
+func ValidateJSONArrayStream(r io.Reader, elemValidator Validator, onResult func(idx int, r *Results) bool, opts ...JSONOption) error {
+	decoder := json.NewDecoder(r)
+	for _, opt := range opts {
+		opt(decoder)
+	}
+
+	start, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, isDelim := start.(json.Delim); !isDelim || delim != '[' {
+		return fmt.Errorf("expected JSON array, got %v", start)
+	}
+
+	for idx := 0; decoder.More(); idx++ {
+		var elem interface{}
+		if err := decoder.Decode(&elem); err != nil {
+			return fmt.Errorf("could not decode array element %d: %v", idx, err)
+		}
+		if !onResult(idx, elemValidator(elem)) {
+			return nil
+		}
+	}
+
+	// Consume the closing bracket
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.New("unexpected data after JSON array")
+	}
+	return nil
+}
+
+// ValidateNDJSON validates each record of newline delimited JSON read from the given reader, such as a log
+// file, against the same Validator, returning the Results for each record in order. Blank lines are skipped.
+// To make failures easy to locate, their messages are prefixed with the line number of the record, counting
+// from 1.
+//
+// A malformed line doesn't abort validation: its entry is a failure at the root of the record describing the
+// problem, and the remaining lines are still validated. The error is only returned if reading fails, in which
+// case the Results for the lines read before the failure are returned along with it.
+func ValidateNDJSON(r io.Reader, v Validator, opts ...JSONOption) ([]*Results, error) {
+	reader := bufio.NewReader(r)
+	var results []*Results
+	for lineNo := 1; ; lineNo++ {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return results, readErr
+		}
+
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			prefix := fmt.Sprintf("line %d: ", lineNo)
+			actual, err := decodeSingleJSON(bytes.NewReader(line), opts)
+			if err != nil {
+				results = append(results, SimpleResult(Path{}, false, "%sinvalid JSON: %v", prefix, err))
+			} else {
+				results = append(results, v(actual).withMessagePrefix(prefix))
+			}
+		}
+
+		if readErr == io.EOF {
+			return results, nil
+		}
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -35,6 +36,10 @@ const (
 	pcSliceIdx
 	//
 	pcScalar
+	// pcMapWildcard is the Type for wildcards matching any map key.
+	pcMapWildcard
+	// pcSliceWildcard is the Type for wildcards matching any slice index.
+	pcSliceWildcard
 )
 
 func (pct pathComponentType) String() string {
@@ -44,6 +49,10 @@ func (pct pathComponentType) String() string {
 		return "slice"
 	} else if pct == pcScalar {
 		return "scalar"
+	} else if pct == pcMapWildcard {
+		return "map wildcard"
+	} else if pct == pcSliceWildcard {
+		return "slice wildcard"
 	} else {
 		// This should never happen, but we don't want to return an
 		// error since that would unnecessarily complicate the fluid API
@@ -59,10 +68,31 @@ type pathComponent struct {
 }
 
 func (pc pathComponent) String() string {
-	if pc.Type == pcSliceIdx {
+	switch pc.Type {
+	case pcSliceIdx:
 		return fmt.Sprintf("[%d]", pc.Index)
+	case pcMapWildcard:
+		return "*"
+	case pcSliceWildcard:
+		return "[*]"
+	default:
+		return escapeKey(pc.Key)
 	}
-	return pc.Key
+}
+
+// escapeKey quotes a map key if ParsePath would not otherwise parse it back into the same key. That is
+// the case for keys containing dots, brackets, or backslashes, as well as the empty key and the * wildcard.
+// Quoted keys take the form ["a.b"], using go string quoting.
+func escapeKey(key string) string {
+	if key == "" || key == "*" || strings.ContainsAny(key, "\\.[") {
+		return "[" + strconv.Quote(key) + "]"
+	}
+	return key
+}
+
+// isWildcard returns true if this pathComponent can match more than one key or index.
+func (pc pathComponent) isWildcard() bool {
+	return pc.Type == pcMapWildcard || pc.Type == pcSliceWildcard
 }
 
 // Path represents the Path within a nested set of maps.
@@ -114,33 +144,95 @@ func (p Path) Last() *pathComponent {
 	return &p[len(p)-1]
 }
 
+// equal returns true if both pathComponents refer to the same key or index.
+func (pc pathComponent) equal(other pathComponent) bool {
+	if pc.Type != other.Type {
+		return false
+	}
+	switch pc.Type {
+	case pcMapKey:
+		return pc.Key == other.Key
+	case pcSliceIdx:
+		return pc.Index == other.Index
+	default:
+		return true
+	}
+}
+
+// Equal returns true if both paths consist of the same components. Components are compared structurally,
+// so a map key "[0]" is not equal to the slice index [0] even though their strings are the same.
+func (p Path) Equal(other Path) bool {
+	return len(p) == len(other) && p.HasPrefix(other)
+}
+
+// HasPrefix returns true if the given Path is equal to the start of this one. Like Equal, this compares
+// components rather than strings, so a.b does not have the prefix a.bc, nor ab the prefix a.
+// Every Path has the empty Path as a prefix.
+func (p Path) HasPrefix(prefix Path) bool {
+	if len(prefix) > len(p) {
+		return false
+	}
+	for idx, pc := range prefix {
+		if !pc.equal(p[idx]) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches returns true if this concrete Path is matched by the given pattern, which may contain wildcards.
+func (p Path) matches(pattern Path) bool {
+	if len(p) != len(pattern) {
+		return false
+	}
+	for idx, pc := range pattern {
+		switch {
+		case pc.Type == pcMapWildcard && p[idx].Type == pcMapKey:
+		case pc.Type == pcSliceWildcard && p[idx].Type == pcSliceIdx:
+		case pc.equal(p[idx]):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Head returns a pointer to the first pathComponent in this Path. If the Path is empty,
+// a nil pointer is returned.
+func (p Path) Head() *pathComponent {
+	if len(p) == 0 {
+		return nil
+	}
+	return &p[0]
+}
+
+// Parent returns a new Path referring to the container of this Path's value, that is, all but the
+// Last pathComponent. The Parent of a Path with a single component is the empty root Path, as is the
+// Parent of the empty Path itself.
+func (p Path) Parent() Path {
+	if len(p) == 0 {
+		return Path{}
+	}
+	out := make(Path, len(p)-1)
+	copy(out, p)
+	return out
+}
+
 // GetFrom takes a map and fetches the given Path from it.
+// If the Path contains wildcards, all matching values are returned in a []interface{}, in the order
+// described by GetAllFrom, and exists is true if there was at least one match.
+// Structs are traversed like maps, with keys named as described in the package documentation, and
+// non-nil pointers along the Path are followed.
 func (p Path) GetFrom(m interface{}) (value interface{}, exists bool) {
+	if p.hasWildcard() {
+		_, values := p.GetAllFrom(m)
+		return values, len(values) > 0
+	}
+
 	value = m
 	exists = true
 	for _, pc := range p {
-		rt := reflect.TypeOf(value)
-		switch rt.Kind() {
-		case reflect.Map:
-			converted := interfaceToMap(value)
-			value, exists = converted[pc.Key]
-		case reflect.Slice:
-			converted := sliceToSliceOfInterfaces(value)
-			if pc.Index < len(converted) {
-				exists = true
-				value = converted[pc.Index]
-			} else {
-				exists = false
-				value = nil
-			}
-		default:
-			// If this case has been reached this means the expected type, say a map,
-			// is actually something else, like a string or an array. In this case we
-			// simply say the value doesn't exist. From a practical perspective this is
-			// the right behavior since it will cause validation to fail.
-			return nil, false
-		}
-
+		value, exists = getComponent(value, pc)
 		if exists == false {
 			return nil, exists
 		}
@@ -149,7 +241,195 @@ func (p Path) GetFrom(m interface{}) (value interface{}, exists bool) {
 	return value, exists
 }
 
-var arrMatcher = regexp.MustCompile("\\[(\\d+)\\]")
+// getComponent fetches the value for a single non-wildcard pathComponent from the given map or slice.
+func getComponent(value interface{}, pc pathComponent) (interface{}, bool) {
+	if value == nil {
+		return nil, false
+	}
+	value = derefPointer(value)
+
+	// Look up values directly, rather than converting the whole collection, which is costly for large ones
+	switch typed := value.(type) {
+	case Map:
+		if pc.Type != pcMapKey {
+			return nil, false
+		}
+		v, exists := typed[pc.Key]
+		return v, exists
+	case map[string]interface{}:
+		if pc.Type != pcMapKey {
+			return nil, false
+		}
+		v, exists := typed[pc.Key]
+		return v, exists
+	case []interface{}:
+		return getSliceComponent(typed, pc)
+	case Slice:
+		return getSliceComponent(typed, pc)
+	}
+
+	if m, isStruct := structMap(value); isStruct {
+		if pc.Type != pcMapKey {
+			return nil, false
+		}
+		v, exists := m[pc.Key]
+		return v, exists
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Map:
+		if pc.Type != pcMapKey {
+			return nil, false
+		}
+		keyType := rv.Type().Key()
+		if keyType.Kind() != reflect.String {
+			// Other keys are formatted as strings, which can only be matched by converting the whole map
+			v, exists := interfaceToMap(value)[pc.Key]
+			return v, exists
+		}
+		v := rv.MapIndex(reflect.ValueOf(pc.Key).Convert(keyType))
+		if !v.IsValid() {
+			return nil, false
+		}
+		return valueToInterface(v), true
+	case reflect.Slice:
+		if pc.Type != pcSliceIdx {
+			return nil, false
+		}
+		idx, inBounds := sliceIndex(pc.Index, rv.Len())
+		if !inBounds {
+			return nil, false
+		}
+		return valueToInterface(rv.Index(idx)), true
+	default:
+		// If this case has been reached this means the expected type, say a map,
+		// is actually something else, like a string or an array. In this case we
+		// simply say the value doesn't exist. From a practical perspective this is
+		// the right behavior since it will cause validation to fail.
+		return nil, false
+	}
+}
+
+// getSliceComponent fetches the element for a single pcSliceIdx pathComponent from the given slice.
+func getSliceComponent(s []interface{}, pc pathComponent) (interface{}, bool) {
+	if pc.Type != pcSliceIdx {
+		return nil, false
+	}
+	idx, inBounds := sliceIndex(pc.Index, len(s))
+	if !inBounds {
+		return nil, false
+	}
+	return s[idx], true
+}
+
+// sliceIndex resolves negative indices, which count back from the end of the slice, and reports whether
+// the resulting index is within a slice of the given length.
+func sliceIndex(idx int, length int) (int, bool) {
+	if idx < 0 {
+		idx += length
+	}
+	return idx, idx >= 0 && idx < length
+}
+
+// hasWildcard returns true if any component of this Path is a wildcard.
+func (p Path) hasWildcard() bool {
+	for _, pc := range p {
+		if pc.isWildcard() {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAllFrom fetches every value matching this Path, which may contain wildcards, from the given map.
+// The concrete Path of each match is returned alongside it. Matches are ordered by slice index and
+// map key. For a Path without wildcards this is equivalent to GetFrom, returning at most one match.
+func (p Path) GetAllFrom(m interface{}) (paths []Path, values []interface{}) {
+	for _, concrete := range p.expandWildcards(m) {
+		if v, exists := concrete.GetFrom(m); exists {
+			paths = append(paths, concrete)
+			values = append(values, v)
+		}
+	}
+	return paths, values
+}
+
+// expandWildcards returns the concrete paths this Path could refer to within the given map by replacing
+// each wildcard with every key or index present at that position. Components after the last wildcard are
+// appended as is, so the returned paths may not exist in the map.
+func (p Path) expandWildcards(m interface{}) []Path {
+	expanded, _ := p.expandWildcardsReportingMissing(m)
+	return expanded
+}
+
+// expandWildcardsReportingMissing is like expandWildcards, but also returns the concrete paths at which a
+// component before the last wildcard is missing, such as items for items.[*].id in a map without items,
+// since nothing beneath them can be expanded.
+func (p Path) expandWildcardsReportingMissing(m interface{}) (expanded []Path, missing []Path) {
+	lastWildcard := -1
+	for idx, pc := range p {
+		if pc.isWildcard() {
+			lastWildcard = idx
+		}
+	}
+	if lastWildcard < 0 {
+		return []Path{p}, nil
+	}
+
+	prefixes := []Path{{}}
+	values := []interface{}{m}
+	for _, pc := range p[:lastWildcard+1] {
+		var nextPrefixes []Path
+		var nextValues []interface{}
+		for idx, prefix := range prefixes {
+			value := values[idx]
+			if !pc.isWildcard() {
+				if v, ok := getComponent(value, pc); ok {
+					nextPrefixes = append(nextPrefixes, prefix.Extend(pc))
+					nextValues = append(nextValues, v)
+				} else {
+					missing = append(missing, prefix.Extend(pc))
+				}
+				continue
+			}
+
+			if value == nil {
+				continue
+			}
+			kind := reflect.TypeOf(value).Kind()
+			converted, isStruct := structMap(value)
+			if pc.Type == pcMapWildcard && (kind == reflect.Map || isStruct) {
+				if !isStruct {
+					converted = interfaceToMap(value)
+				}
+				keys := make([]string, 0, len(converted))
+				for k := range converted {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				for _, k := range keys {
+					nextPrefixes = append(nextPrefixes, prefix.ExtendMap(k))
+					nextValues = append(nextValues, converted[k])
+				}
+			} else if pc.Type == pcSliceWildcard && kind == reflect.Slice {
+				for i, v := range sliceToSliceOfInterfaces(value) {
+					nextPrefixes = append(nextPrefixes, prefix.ExtendSlice(i))
+					nextValues = append(nextValues, v)
+				}
+			}
+		}
+		prefixes, values = nextPrefixes, nextValues
+	}
+
+	expanded = make([]Path, len(prefixes))
+	for idx, prefix := range prefixes {
+		expanded[idx] = prefix.Concat(p[lastWildcard+1:])
+	}
+	return expanded, missing
+}
+
+var arrMatcher = regexp.MustCompile("^\\[(-?\\d+)\\]$")
 
 // InvalidPathString is the error type returned from unparseable paths.
 type InvalidPathString string
@@ -159,14 +439,40 @@ func (ps InvalidPathString) Error() string {
 }
 
 // ParsePath parses a Path of form key.[0].otherKey.[1] into a Path object.
+// The wildcards * and [*] match any map key and any slice index respectively, as in items.[*].price.
+// In a schema, a wildcard over an empty collection has nothing to check, while a missing collection fails
+// as a missing key, unless the IsDef is Optional.
+// Negative slice indices count back from the end of the slice, so items.[-1] is the last item.
+//
+// Keys containing dots or brackets, such as those common in observability data, can be quoted like
+// ["http.response.status"], using go string quoting. Alternatively a backslash escapes the character
+// following it, so http\.response\.status is the same single key. Quoted and escaped keys are always
+// literal keys, so ["*"] and \[0] are keys rather than a wildcard and a slice index.
+// The empty string parses to the empty, root, Path.
+//
+// ParsePath is the inverse of Path.String, so ParsePath(p.String()) is equal to p for any Path p.
 func ParsePath(in string) (p Path, err error) {
-	keyParts := strings.Split(in, ".")
+	if in == "" {
+		return Path{}, nil
+	}
+
+	keyParts, literal, err := splitPath(in)
+	if err != nil {
+		return nil, err
+	}
 
 	p = make(Path, len(keyParts))
 	for idx, part := range keyParts {
-		r := arrMatcher.FindStringSubmatch(part)
 		pc := pathComponent{Index: -1}
-		if len(r) > 0 {
+		if literal[idx] {
+			// Quoted or escaped parts are always literal map keys
+			pc.Type = pcMapKey
+			pc.Key = part
+		} else if part == "*" {
+			pc.Type = pcMapWildcard
+		} else if part == "[*]" {
+			pc.Type = pcSliceWildcard
+		} else if r := arrMatcher.FindStringSubmatch(part); len(r) > 0 {
 			pc.Type = pcSliceIdx
 			// Cannot fail, validated by regexp already
 			pc.Index, err = strconv.Atoi(r[1])
@@ -186,6 +492,93 @@ func ParsePath(in string) (p Path, err error) {
 	return p, nil
 }
 
+// splitPath splits a path string on dots that are neither escaped nor quoted, removing the escapes and quotes.
+// For each part it also reports whether it was quoted or escaped, since those parts must be treated as
+// literal keys.
+func splitPath(in string) (parts []string, literal []bool, err error) {
+	var current strings.Builder
+	currentLiteral := false
+	for i := 0; i < len(in); i++ {
+		c := in[i]
+		switch {
+		case c == '\\':
+			if i+1 >= len(in) {
+				// A trailing backslash doesn't escape anything
+				return nil, nil, InvalidPathString(in)
+			}
+			i++
+			current.WriteByte(in[i])
+			currentLiteral = true
+		case c == '.':
+			parts = append(parts, current.String())
+			literal = append(literal, currentLiteral)
+			current.Reset()
+			currentLiteral = false
+		case c == '[' && current.Len() == 0 && !currentLiteral && strings.HasPrefix(in[i:], "[\""):
+			end := quotedKeyEnd(in, i+1)
+			if end < 0 || end+1 >= len(in) || in[end+1] != ']' {
+				return nil, nil, InvalidPathString(in)
+			}
+			// The quoted key must make up the whole part
+			if end+2 < len(in) && in[end+2] != '.' {
+				return nil, nil, InvalidPathString(in)
+			}
+
+			key, err := strconv.Unquote(in[i+1 : end+1])
+			if err != nil {
+				return nil, nil, InvalidPathString(in)
+			}
+			current.WriteString(key)
+			currentLiteral = true
+			i = end + 1
+		default:
+			current.WriteByte(c)
+		}
+	}
+
+	parts = append(parts, current.String())
+	literal = append(literal, currentLiteral)
+	return parts, literal, nil
+}
+
+// eachPathStringAncestor calls f with the string form of every proper ancestor of the path with the given
+// string form, from the outermost in. For instance the ancestors of a.b.[0] are a and a.b. This works on the
+// string directly, without parsing it into a Path, since it is used on every result in Strict.
+func eachPathStringAncestor(path string, f func(ancestor string)) {
+	atPartStart := true
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; {
+		case c == '\\':
+			i++
+			atPartStart = false
+		case c == '.':
+			f(path[:i])
+			atPartStart = true
+		case c == '[' && atPartStart && strings.HasPrefix(path[i:], "[\""):
+			if end := quotedKeyEnd(path, i+1); end >= 0 {
+				i = end
+			}
+			atPartStart = false
+		default:
+			atPartStart = false
+		}
+	}
+}
+
+// quotedKeyEnd returns the index of the double quote closing the quoted string starting at the given index,
+// or -1 if it is never closed.
+func quotedKeyEnd(in string, start int) int {
+	for j := start + 1; j < len(in); j++ {
+		switch in[j] {
+		case '\\':
+			j++
+		case '"':
+			return j
+		}
+	}
+	return -1
+}
+
 // MustParsePath is a convenience method for parsing paths that have been previously validated
 func MustParsePath(in string) Path {
 	out, err := ParsePath(in)
@@ -194,3 +587,117 @@ func MustParsePath(in string) Path {
 	}
 	return out
 }
+
+// UnsupportedJSONPathFeature is the error type returned by ParseJSONPath for valid JSONPath expressions
+// using features that cannot be represented as a Path.
+type UnsupportedJSONPathFeature struct {
+	Expr    string
+	Feature string
+}
+
+func (e UnsupportedJSONPathFeature) Error() string {
+	return fmt.Sprintf("Unsupported JSONPath feature %s in %#v", e.Feature, e.Expr)
+}
+
+// ParseJSONPath parses a JSONPath expression such as $.items[*].id into a Path. Only the subset of JSONPath
+// that maps directly onto Path is supported:
+//
+//   - the root $, which must start every expression
+//   - child keys in dot notation, as in $.a.b
+//   - child keys in bracket notation, as in $['a.b'] or $["a.b"]
+//   - slice indices, as in $.a[0], including negative indices counting back from the end, as in $.a[-1]
+//   - the wildcards .* for any map key and [*] for any slice index
+//
+// Recursive descent (..), array slices ([0:2]), unions ([0,1]), filters ([?(...)]) and script expressions
+// ([(...)]) are not supported and produce an UnsupportedJSONPathFeature error. Other malformed expressions
+// produce an InvalidPathString error.
+func ParseJSONPath(expr string) (Path, error) {
+	if !strings.HasPrefix(expr, "$") {
+		return nil, InvalidPathString(expr)
+	}
+
+	p := Path{}
+	for i := 1; i < len(expr); {
+		switch expr[i] {
+		case '.':
+			if i+1 < len(expr) && expr[i+1] == '.' {
+				return nil, UnsupportedJSONPathFeature{expr, "recursive descent (..)"}
+			}
+			end := i + 1
+			for end < len(expr) && expr[end] != '.' && expr[end] != '[' {
+				end++
+			}
+			key := expr[i+1 : end]
+			if key == "" {
+				return nil, InvalidPathString(expr)
+			} else if key == "*" {
+				p = p.Extend(pathComponent{Type: pcMapWildcard, Index: -1})
+			} else {
+				p = p.ExtendMap(key)
+			}
+			i = end
+		case '[':
+			if i+1 < len(expr) && (expr[i+1] == '\'' || expr[i+1] == '"') {
+				key, end, ok := unquoteJSONPathKey(expr, i+1)
+				if !ok || end+1 >= len(expr) || expr[end+1] != ']' {
+					return nil, InvalidPathString(expr)
+				}
+				p = p.ExtendMap(key)
+				i = end + 2
+				continue
+			}
+
+			end := strings.IndexByte(expr[i:], ']')
+			if end < 0 {
+				return nil, InvalidPathString(expr)
+			}
+			end += i
+			inner := expr[i+1 : end]
+			switch {
+			case inner == "*":
+				p = p.Extend(pathComponent{Type: pcSliceWildcard, Index: -1})
+			case strings.HasPrefix(inner, "?"):
+				return nil, UnsupportedJSONPathFeature{expr, "filter expressions ([?(...)])"}
+			case strings.HasPrefix(inner, "("):
+				return nil, UnsupportedJSONPathFeature{expr, "script expressions ([(...)])"}
+			case strings.Contains(inner, ":"):
+				return nil, UnsupportedJSONPathFeature{expr, "array slices ([start:end])"}
+			case strings.Contains(inner, ","):
+				return nil, UnsupportedJSONPathFeature{expr, "unions ([a,b])"}
+			default:
+				idx, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, InvalidPathString(expr)
+				}
+				p = p.ExtendSlice(idx)
+			}
+			i = end + 1
+		default:
+			return nil, InvalidPathString(expr)
+		}
+	}
+
+	return p, nil
+}
+
+// unquoteJSONPathKey reads the single or double quoted key starting at the given index, returning the
+// unescaped key and the index of the closing quote.
+func unquoteJSONPathKey(expr string, start int) (key string, end int, ok bool) {
+	quote := expr[start]
+	var b strings.Builder
+	for j := start + 1; j < len(expr); j++ {
+		switch expr[j] {
+		case '\\':
+			if j+1 >= len(expr) {
+				return "", 0, false
+			}
+			j++
+			b.WriteByte(expr[j])
+		case quote:
+			return b.String(), j, true
+		default:
+			b.WriteByte(expr[j])
+		}
+	}
+	return "", 0, false
+}
@@ -20,6 +20,8 @@ package lookslike
 import (
 	"reflect"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestPathComponentType_String(t *testing.T) {
@@ -193,6 +195,11 @@ func TestPath_String(t *testing.T) {
 			Path{}.ExtendMap("foo").ExtendSlice(123).ExtendMap("bar"),
 			"foo.[123].bar",
 		},
+		{
+			"dotted key",
+			Path{}.ExtendMap("http.response.status").ExtendMap("code"),
+			`["http.response.status"].code`,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -234,6 +241,35 @@ func TestPath_Last(t *testing.T) {
 	}
 }
 
+func TestPath_Head(t *testing.T) {
+	assert.Nil(t, Path{}.Head())
+	assert.Equal(t, &pathComponent{pcMapKey, "foo", -1}, MustParsePath("foo").Head())
+	assert.Equal(t, &pathComponent{pcSliceIdx, "", 1}, MustParsePath("[1].foo.bar").Head())
+}
+
+func TestPath_Parent(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Path
+		want Path
+	}{
+		{"empty Path", Path{}, Path{}},
+		{"one element", MustParsePath("foo"), Path{}},
+		{"many elements", MustParsePath("foo.[0].bar"), MustParsePath("foo.[0]")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.p.Parent())
+		})
+	}
+
+	// The parent is a copy, extending it doesn't modify the original
+	p := MustParsePath("foo.bar")
+	p.Parent().ExtendMap("baz")
+	p.Parent()[0].Key = "changed"
+	assert.Equal(t, "foo.bar", p.String())
+}
+
 func TestPath_GetFrom(t *testing.T) {
 	fooPath := Path{}.ExtendMap("foo")
 	complexPath := Path{}.ExtendMap("foo").ExtendSlice(0).ExtendMap("bar").ExtendSlice(1)
@@ -272,6 +308,62 @@ func TestPath_GetFrom(t *testing.T) {
 			nil,
 			false,
 		},
+		{
+			"nil intermediate value",
+			complexPath,
+			Map{"foo": nil},
+			nil,
+			false,
+		},
+		{
+			"map key into slice",
+			Path{}.ExtendMap("foo").ExtendMap("bar"),
+			Map{"foo": []int{1}},
+			nil,
+			false,
+		},
+		{
+			"negative index",
+			MustParsePath("foo.[-1]"),
+			Map{"foo": []string{"a", "b", "c"}},
+			"c",
+			true,
+		},
+		{
+			"negative index at start",
+			MustParsePath("foo.[-3]"),
+			Map{"foo": []string{"a", "b", "c"}},
+			"a",
+			true,
+		},
+		{
+			"negative index out of range",
+			MustParsePath("foo.[-4]"),
+			Map{"foo": []string{"a", "b", "c"}},
+			nil,
+			false,
+		},
+		{
+			"negative index in empty slice",
+			MustParsePath("foo.[-1]"),
+			Map{"foo": []string{}},
+			nil,
+			false,
+		},
+		{
+			"wildcards return all matches",
+			MustParsePath("foo.[*].bar"),
+			Map{"foo": []interface{}{Map{"bar": 1}, Map{"baz": 2}, Map{"bar": 3}}},
+			[]interface{}{1, 3},
+			true,
+		},
+		{
+			"wildcards without matches",
+			MustParsePath("foo.*"),
+			Map{"foo": Map{}},
+			[]interface{}(nil),
+			false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -286,6 +378,86 @@ func TestPath_GetFrom(t *testing.T) {
 	}
 }
 
+// testProtoMessage mimics the struct protoc-gen-go generates for a message with a oneof.
+type testProtoMessage struct {
+	state         int
+	UserId        string            `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	DisplayName   string            `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3"`
+	Address       *testProtoAddress `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	Contact       isTestContact     `protobuf_oneof:"contact"`
+	XXX_sizecache int32             `json:"-"`
+}
+
+type testProtoAddress struct {
+	City string `protobuf:"bytes,1,opt,name=city,proto3" json:"city,omitempty"`
+}
+
+type isTestContact interface{ isTestContact() }
+
+type testProtoMessage_Email struct {
+	Email string `protobuf:"bytes,4,opt,name=email,proto3,oneof"`
+}
+
+func (*testProtoMessage_Email) isTestContact() {}
+
+type testEmbedded struct {
+	Shared string
+	Inner  string `json:"inner"`
+}
+
+type testStruct struct {
+	testEmbedded
+	Shared  string `json:"shared"`
+	GoName  int
+	private string
+}
+
+func TestPath_GetFromStruct(t *testing.T) {
+	msg := &testProtoMessage{
+		UserId:      "u1",
+		DisplayName: "User",
+		Address:     &testProtoAddress{City: "x"},
+		Contact:     &testProtoMessage_Email{Email: "u@example.net"},
+	}
+	tests := []struct {
+		path       string
+		arg        interface{}
+		wantValue  interface{}
+		wantExists bool
+	}{
+		{"user_id", msg, "u1", true},
+		{"display_name", msg, "User", true},
+		{"address.city", msg, "x", true},
+		{"email", msg, "u@example.net", true},
+		{"contact", msg, nil, false},
+		{"UserId", msg, nil, false},
+		{"XXX_sizecache", msg, nil, false},
+		{"state", msg, nil, false},
+		{"email", &testProtoMessage{}, nil, false},
+		{"msg.address.city", Map{"msg": msg}, "x", true},
+		{"msg.[0]", Map{"msg": msg}, nil, false},
+		{"shared", testStruct{Shared: "outer", testEmbedded: testEmbedded{Shared: "inner"}}, "outer", true},
+		{"inner", testStruct{testEmbedded: testEmbedded{Inner: "promoted"}}, "promoted", true},
+		{"GoName", testStruct{GoName: 1}, 1, true},
+		{"private", testStruct{private: "p"}, nil, false},
+		{"user_id", (*testProtoMessage)(nil), nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			gotValue, gotExists := MustParsePath(tt.path).GetFrom(tt.arg)
+			assert.Equal(t, tt.wantValue, gotValue)
+			assert.Equal(t, tt.wantExists, gotExists)
+		})
+	}
+
+	validator := MustCompile(Map{
+		"user_id": IsNonEmptyString,
+		"address": Map{"city": "x"},
+		"email":   IsEmail,
+	})
+	assertResults(t, validator(msg))
+}
+
 func TestParsePath(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -305,6 +477,101 @@ func TestParsePath(t *testing.T) {
 			Path{}.ExtendMap("foo").ExtendSlice(0).ExtendMap("bar").ExtendSlice(1).ExtendMap("baz"),
 			false,
 		},
+		{
+			"empty",
+			"",
+			Path{},
+			false,
+		},
+		{
+			"escaped dot",
+			"a\\.b.c",
+			Path{}.ExtendMap("a.b").ExtendMap("c"),
+			false,
+		},
+		{
+			"brackets inside a key",
+			"weird[key]",
+			Path{}.ExtendMap("weird[key]"),
+			false,
+		},
+		{
+			"escaped slice index",
+			"\\[0]",
+			Path{}.ExtendMap("[0]"),
+			false,
+		},
+		{
+			"escaped wildcard",
+			"\\*",
+			Path{}.ExtendMap("*"),
+			false,
+		},
+		{
+			"quoted key",
+			`["http.response.status"]`,
+			Path{}.ExtendMap("http.response.status"),
+			false,
+		},
+		{
+			"nested quoted key",
+			`foo.["a.b"].[0].["c\"]"]`,
+			Path{}.ExtendMap("foo").ExtendMap("a.b").ExtendSlice(0).ExtendMap(`c"]`),
+			false,
+		},
+		{
+			"quoted empty key",
+			`a.[""]`,
+			Path{}.ExtendMap("a").ExtendMap(""),
+			false,
+		},
+		{
+			"quoted wildcard",
+			`["*"]`,
+			Path{}.ExtendMap("*"),
+			false,
+		},
+		{
+			"unterminated quote",
+			`["a.b`,
+			nil,
+			true,
+		},
+		{
+			"text after quote",
+			`["a"]b`,
+			nil,
+			true,
+		},
+		{
+			"trailing backslash",
+			"foo\\",
+			nil,
+			true,
+		},
+		{
+			"empty part",
+			"foo..bar",
+			nil,
+			true,
+		},
+		{
+			"negative index",
+			"foo.[-1]",
+			Path{}.ExtendMap("foo").ExtendSlice(-1),
+			false,
+		},
+		{
+			"wildcards",
+			"foo.*.bar.[*]",
+			Path{
+				pathComponent{pcMapKey, "foo", -1},
+				pathComponent{pcMapWildcard, "", -1},
+				pathComponent{pcMapKey, "bar", -1},
+				pathComponent{pcSliceWildcard, "", -1},
+			},
+			false,
+		},
 		// TODO: The validation and testing for this needs to be better
 	}
 	for _, tt := range tests {
@@ -320,3 +587,154 @@ func TestParsePath(t *testing.T) {
 		})
 	}
 }
+
+func TestPath_GetAllFrom(t *testing.T) {
+	m := Map{
+		"items": []interface{}{
+			Map{"price": 1, "tags": Map{"b": "y", "a": "x"}},
+			Map{"name": "no price"},
+			Map{"price": 3},
+		},
+	}
+
+	paths, values := MustParsePath("items.[*].price").GetAllFrom(m)
+	assert.Equal(t, []Path{MustParsePath("items.[0].price"), MustParsePath("items.[2].price")}, paths)
+	assert.Equal(t, []interface{}{1, 3}, values)
+
+	paths, values = MustParsePath("items.[*].tags.*").GetAllFrom(m)
+	assert.Equal(t, []Path{MustParsePath("items.[0].tags.a"), MustParsePath("items.[0].tags.b")}, paths)
+	assert.Equal(t, []interface{}{"x", "y"}, values)
+
+	// Wildcards of the wrong type don't match
+	paths, _ = MustParsePath("items.*").GetAllFrom(m)
+	assert.Empty(t, paths)
+
+	// Without wildcards this behaves like GetFrom
+	paths, values = MustParsePath("items.[2].price").GetAllFrom(m)
+	assert.Equal(t, []Path{MustParsePath("items.[2].price")}, paths)
+	assert.Equal(t, []interface{}{3}, values)
+}
+
+func TestPath_Equal(t *testing.T) {
+	assert.True(t, Path{}.Equal(Path{}))
+	assert.True(t, MustParsePath("a.[0].b").Equal(MustParsePath("a.[0].b")))
+	assert.True(t, MustParsePath("a.*.[*]").Equal(MustParsePath("a.*.[*]")))
+	assert.False(t, MustParsePath("a.[0].b").Equal(MustParsePath("a.[1].b")))
+	assert.False(t, MustParsePath("a.b").Equal(MustParsePath("a")))
+	assert.False(t, MustParsePath("a").Equal(MustParsePath("a.b")))
+	assert.False(t, MustParsePath("a.*").Equal(MustParsePath("a.[*]")))
+	// Structurally different, even though the strings are the same
+	assert.False(t, Path{}.ExtendMap("[0]").Equal(Path{}.ExtendSlice(0)))
+}
+
+func TestPath_HasPrefix(t *testing.T) {
+	p := MustParsePath("a.b.[0]")
+
+	assert.True(t, p.HasPrefix(Path{}))
+	assert.True(t, p.HasPrefix(MustParsePath("a")))
+	assert.True(t, p.HasPrefix(MustParsePath("a.b")))
+	assert.True(t, p.HasPrefix(p))
+	assert.False(t, p.HasPrefix(MustParsePath("a.b.[0].c")))
+	assert.False(t, p.HasPrefix(MustParsePath("a.bc")))
+	assert.False(t, MustParsePath("ab").HasPrefix(MustParsePath("a")))
+	assert.False(t, p.HasPrefix(MustParsePath("a.b.[1]")))
+}
+
+func TestPath_RoundTrip(t *testing.T) {
+	paths := []Path{
+		{},
+		Path{}.ExtendMap("foo"),
+		Path{}.ExtendMap("foo").ExtendSlice(0).ExtendMap("bar"),
+		Path{}.ExtendSlice(-1),
+		Path{}.ExtendMap("a.b"),
+		Path{}.ExtendMap("weird[key]"),
+		Path{}.ExtendMap("[0]"),
+		Path{}.ExtendMap("[*]"),
+		Path{}.ExtendMap("*"),
+		Path{}.ExtendMap("**"),
+		Path{}.ExtendMap(`back\slash`),
+		Path{}.ExtendMap(`trailing\`),
+		Path{}.ExtendMap("a.").ExtendMap(".b").ExtendSlice(3),
+		MustParsePath("a.*.[*]"),
+		Path{}.ExtendMap(""),
+		Path{}.ExtendMap("a").ExtendMap("").ExtendMap("b"),
+		Path{}.ExtendMap(`["quoted"]`),
+		Path{}.ExtendMap("unicode.ключ"),
+	}
+
+	for _, p := range paths {
+		t.Run(p.String(), func(t *testing.T) {
+			parsed, err := ParsePath(p.String())
+			assert.NoError(t, err)
+			assert.True(t, p.Equal(parsed), "%#v != %#v", p, parsed)
+		})
+	}
+}
+
+func TestParseJSONPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		want    Path
+		wantErr error
+	}{
+		{"root", "$", Path{}, nil},
+		{"dotted", "$.a.b[0]", MustParsePath("a.b.[0]"), nil},
+		{"wildcards", "$.items[*].*", MustParsePath("items.[*].*"), nil},
+		{"negative index", "$.items[-1].id", MustParsePath("items.[-1].id"), nil},
+		{"nested slices", "$[0][1]", MustParsePath("[0].[1]"), nil},
+		{"single quoted key", "$['a.b'].c", Path{}.ExtendMap("a.b").ExtendMap("c"), nil},
+		{"double quoted key", `$["it's"]`, Path{}.ExtendMap("it's"), nil},
+		{"escaped quote", `$['it\'s']`, Path{}.ExtendMap("it's"), nil},
+		{"quoted star", "$['*']", Path{}.ExtendMap("*"), nil},
+		{"missing root", "a.b", nil, InvalidPathString("a.b")},
+		{"trailing dot", "$.a.", nil, InvalidPathString("$.a.")},
+		{"unterminated bracket", "$.a[0", nil, InvalidPathString("$.a[0")},
+		{"unterminated quote", "$['a]", nil, InvalidPathString("$['a]")},
+		{"bad index", "$.a[x]", nil, InvalidPathString("$.a[x]")},
+		{"recursive descent", "$..id", nil, UnsupportedJSONPathFeature{"$..id", "recursive descent (..)"}},
+		{"slice", "$.a[0:2]", nil, UnsupportedJSONPathFeature{"$.a[0:2]", "array slices ([start:end])"}},
+		{"union", "$.a[0,1]", nil, UnsupportedJSONPathFeature{"$.a[0,1]", "unions ([a,b])"}},
+		{"filter", "$.a[?(@.x)]", nil, UnsupportedJSONPathFeature{"$.a[?(@.x)]", "filter expressions ([?(...)])"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseJSONPath(tt.expr)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEachPathStringAncestor(t *testing.T) {
+	paths := []Path{
+		MustParsePath("a.b.[0].c"),
+		Path{}.ExtendMap("a.b").ExtendMap("c").ExtendMap(`x"].y`),
+		Path{}.ExtendMap(`back\slash`).ExtendMap("d"),
+		MustParsePath("single"),
+		{},
+	}
+	for _, p := range paths {
+		var expected, got []string
+		for i := 1; i < len(p); i++ {
+			expected = append(expected, p[:i].String())
+		}
+		eachPathStringAncestor(p.String(), func(ancestor string) {
+			got = append(got, ancestor)
+		})
+		assert.Equal(t, expected, got, p.String())
+	}
+}
+
+func BenchmarkGetFrom(b *testing.B) {
+	doc, _ := nestedBenchmarkDocument()
+	path := MustParsePath("service5.hosts.[3].os.version.minor")
+	b.ReportAllocs()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, exists := path.GetFrom(doc); !exists {
+			b.Fatal("expected path to exist")
+		}
+	}
+}
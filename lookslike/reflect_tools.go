@@ -18,43 +18,393 @@
 package lookslike
 
 import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
 	"reflect"
+	"strings"
 )
 
+// interfaceToMap converts a map into a Map. Maps that already are a Map or a map[string]interface{} are
+// returned without copying, so the result must not be modified. Keys that aren't strings are formatted
+// with fmt, so an int key of 1 becomes "1", much as encoding/json encodes them.
 func interfaceToMap(o interface{}) Map {
+	return convertMap(o, valueToInterface)
+}
+
+// convertMap is like interfaceToMap, but converts the values of typed maps with the given valueConverter.
+func convertMap(o interface{}, convert valueConverter) Map {
+	switch typed := o.(type) {
+	case Map:
+		return typed
+	case map[string]interface{}:
+		return Map(typed)
+	}
+
 	newMap := Map{}
 	rv := reflect.ValueOf(o)
 
 	for _, key := range rv.MapKeys() {
 		mapV := rv.MapIndex(key)
-		keyStr := key.Interface().(string)
-		var value interface{}
+		keyStr := mapKeyString(key)
+		newMap[keyStr] = convert(mapV)
+	}
+	return newMap
+}
 
-		if !mapV.IsNil() {
-			value = mapV.Interface().(interface{})
-		}
+// mapKeyString returns the key of a map as a string, as per interfaceToMap.
+func mapKeyString(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return key.String()
+	}
+	return fmt.Sprintf("%v", key.Interface())
+}
 
-		newMap[keyStr] = value
+// valueConverter returns the value held by a field of a struct, or an element of a typed map or slice.
+type valueConverter func(v reflect.Value) interface{}
+
+// valueToInterface returns the value held by the given reflect.Value, or nil for nil interfaces. Non-nil
+// pointers are dereferenced as per derefPointer.
+func valueToInterface(v reflect.Value) interface{} {
+	return derefPointer(rawValueToInterface(v))
+}
+
+// rawValueToInterface is like valueToInterface, but leaves pointers as they are, so that walk can recognize
+// values it's already within by their addresses.
+func rawValueToInterface(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Interface && v.IsNil() {
+		return nil
 	}
-	return newMap
+	return v.Interface()
+}
+
+// derefPointer follows non-nil pointers, such as the *string fields used for optional values in structs, to
+// the values they point to. Nil pointers are returned as they are, so that isNilPointer can tell them apart
+// from other values.
+func derefPointer(v interface{}) interface{} {
+	switch v.(type) {
+	case nil, Map, map[string]interface{}, Slice, []interface{}, string, float64, bool:
+		return v
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr {
+		return v
+	}
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	return rv.Interface()
+}
+
+// isNilPointer reports whether the given value is a nil pointer, as opposed to an untyped nil or other typed nil.
+func isNilPointer(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
 }
 
+// sliceToSliceOfInterfaces converts a slice or array into a []interface{}. Slices that already are a
+// []interface{} are returned without copying, so the result must not be modified.
 func sliceToSliceOfInterfaces(o interface{}) []interface{} {
+	return convertSlice(o, valueToInterface)
+}
+
+// convertSlice is like sliceToSliceOfInterfaces, but converts the elements of typed slices with the given
+// valueConverter.
+func convertSlice(o interface{}, convert valueConverter) []interface{} {
+	switch typed := o.(type) {
+	case []interface{}:
+		return typed
+	case Slice:
+		return typed
+	case LaxSlice:
+		return typed
+	}
+
 	rv := reflect.ValueOf(o)
 	converted := make([]interface{}, rv.Len())
 	for i := 0; i < rv.Len(); i++ {
-		var indexV = rv.Index(i)
-		var convertedValue interface{}
-		if indexV.Type().Kind() == reflect.Interface {
-			if !indexV.IsNil() {
-				convertedValue = indexV.Interface().(interface{})
-			} else {
-				convertedValue = nil
+		converted[i] = convert(rv.Index(i))
+	}
+	return converted
+}
+
+// toFloat64 coerces any of go's numeric kinds to a float64 via reflection. Valid json.Number
+// values, as produced by json.Decoder.UseNumber, are accepted as well. The second return
+// value is false if the given value is not numeric.
+func toFloat64(o interface{}) (float64, bool) {
+	if o == nil {
+		return 0, false
+	}
+
+	if jn, ok := o.(json.Number); ok {
+		f, err := jn.Float64()
+		return f, err == nil
+	}
+
+	rv := reflect.ValueOf(o)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// isNil reports whether the given value is nil, including typed nils such as a (*T)(nil) stored
+// in an interface{}, which do not compare equal to nil directly.
+func isNil(o interface{}) bool {
+	if o == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(o)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return rv.IsNil()
+	default:
+		return false
+	}
+}
+
+// toInt64 coerces go's integer kinds, and json.Number values holding integers, to an int64.
+// The second return value is false if the value is not an integer or doesn't fit in an int64.
+func toInt64(o interface{}) (int64, bool) {
+	if o == nil {
+		return 0, false
+	}
+
+	if jn, ok := o.(json.Number); ok {
+		i, err := jn.Int64()
+		return i, err == nil
+	}
+
+	rv := reflect.ValueOf(o)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := rv.Uint()
+		return int64(u), u <= math.MaxInt64
+	default:
+		return 0, false
+	}
+}
+
+// numbersEqual compares two numeric values of any kind, including json.Number. Integers are compared
+// exactly as int64s, anything else is compared as float64s. The second return value is false if either
+// value is not numeric.
+func numbersEqual(a, b interface{}) (equal bool, ok bool) {
+	af, aOk := toFloat64(a)
+	bf, bOk := toFloat64(b)
+	if !aOk || !bOk {
+		return false, false
+	}
+
+	if ai, ok := toInt64(a); ok {
+		if bi, ok := toInt64(b); ok {
+			return ai == bi, true
+		}
+	}
+
+	return af == bf, true
+}
+
+// structFieldKey returns the key the given struct field is encoded under by encoding/json, which is the name
+// from its json tag if it has one, or the field name otherwise. Fields of protobuf generated structs without
+// a json tag use the field name from their protobuf tag instead. Unexported fields and fields tagged json:"-"
+// are skipped.
+func structFieldKey(f reflect.StructField) (key string, skip bool) {
+	if f.PkgPath != "" && !(f.Anonymous && f.Type.Kind() == reflect.Struct) {
+		return "", true
+	}
+
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	if idx := strings.IndexByte(tag, ','); idx >= 0 {
+		tag = tag[:idx]
+	}
+	if tag != "" {
+		return tag, false
+	}
+	if name := protobufName(f); name != "" {
+		return name, false
+	}
+	return f.Name, false
+}
+
+// protobufName returns the name of the proto field from the protobuf tag of a generated struct field, such as
+// user_id for `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3"`, or "" if there is none.
+func protobufName(f reflect.StructField) string {
+	for _, part := range strings.Split(f.Tag.Get("protobuf"), ",") {
+		if strings.HasPrefix(part, "name=") {
+			return strings.TrimPrefix(part, "name=")
+		}
+	}
+	return ""
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// structMap returns the fields of the given struct, or pointer to a struct, in a Map as per structToMap, so
+// that it can be traversed like one. Structs that encode themselves, by implementing json.Marshaler or
+// encoding.TextMarshaler as time.Time does, aren't encoded as objects by encoding/json, and so are treated as
+// values rather than traversed. The second return value is false if the value isn't a struct to traverse.
+func structMap(v interface{}) (Map, bool) {
+	return convertStruct(v, valueToInterface)
+}
+
+// convertStruct is like structMap, but converts the values of fields with the given valueConverter.
+func convertStruct(v interface{}, convert valueConverter) (Map, bool) {
+	if v == nil {
+		return nil, false
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	for _, t := range []reflect.Type{rv.Type(), reflect.PtrTo(rv.Type())} {
+		if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+			return nil, false
+		}
+	}
+	return structToMap(rv, convert), true
+}
+
+// structToMap returns the fields of the given struct value in a Map, keyed as described by structFieldKey.
+// As with encoding/json, the fields of embedded structs without a json name are promoted, with the fields of
+// the outer struct taking precedence. A protobuf oneof field, marked by a protobuf_oneof tag, holds a
+// wrapper struct for whichever of its members is set, and that member is included under its own name,
+// rather than the oneof being included under its name.
+func structToMap(rv reflect.Value, convert valueConverter) Map {
+	m := Map{}
+	promoted := Map{}
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Tag.Get("protobuf_oneof") != "" {
+			if f.PkgPath == "" {
+				addOneofMember(m, rv.Field(i), convert)
+			}
+			continue
+		}
+
+		key, skip := structFieldKey(f)
+		if skip {
+			continue
+		}
+
+		if f.Anonymous && f.Tag.Get("json") == "" && f.Type.Kind() == reflect.Struct {
+			for k, v := range structToMap(rv.Field(i), convert) {
+				promoted[k] = v
 			}
-		} else {
-			convertedValue = indexV.Interface().(interface{})
+			continue
 		}
-		converted[i] = convertedValue
+
+		m[key] = convert(rv.Field(i))
 	}
-	return converted
+
+	for k, v := range promoted {
+		if _, exists := m[k]; !exists {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// addOneofMember adds the member set in the given protobuf oneof field, if any, to the given Map. Generated
+// code stores it as a pointer to a wrapper struct with a single field, the member itself.
+func addOneofMember(m Map, oneof reflect.Value, convert valueConverter) {
+	if oneof.Kind() != reflect.Interface || oneof.IsNil() {
+		return
+	}
+	wrapper := oneof.Elem()
+	if wrapper.Kind() == reflect.Ptr {
+		if wrapper.IsNil() {
+			return
+		}
+		wrapper = wrapper.Elem()
+	}
+	if wrapper.Kind() != reflect.Struct || wrapper.NumField() != 1 {
+		return
+	}
+
+	if key, skip := structFieldKey(wrapper.Type().Field(0)); !skip {
+		m[key] = convert(wrapper.Field(0))
+	}
+}
+
+// collectionLen returns the length of the given value if it's a map or a slice, checking the types found in
+// decoded JSON before falling back to reflection.
+func collectionLen(v interface{}) (length int, isCollection bool) {
+	switch typed := v.(type) {
+	case nil:
+		return 0, false
+	case Map:
+		return len(typed), true
+	case map[string]interface{}:
+		return len(typed), true
+	case Slice:
+		return len(typed), true
+	case []interface{}:
+		return len(typed), true
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice:
+		return rv.Len(), true
+	default:
+		return 0, false
+	}
+}
+
+// valueID identifies a value that can contain itself: a map, a non-empty slice or a non-nil pointer. The type
+// is part of it, since a pointer to a struct has the same address as a pointer to its first field.
+type valueID struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// valueIdentity returns the valueID of the given value, which is shared by every reference to it. The second
+// return value is false for other values. Empty slices may share their address with unrelated values, but
+// having no elements they can't contain themselves anyway.
+func valueIdentity(v interface{}) (id valueID, ok bool) {
+	switch v.(type) {
+	case nil, string, float64, bool:
+		return valueID{}, false
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map, reflect.Ptr:
+		if rv.IsNil() {
+			return valueID{}, false
+		}
+	case reflect.Slice:
+		if rv.Len() == 0 {
+			return valueID{}, false
+		}
+	default:
+		return valueID{}, false
+	}
+	return valueID{rv.Pointer(), rv.Type()}, true
 }
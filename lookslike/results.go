@@ -17,14 +17,45 @@
 
 package lookslike
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
 
 // Results the results of executing a schema.
 // They are a flattened map (using dotted paths) of all the values []ValueResult representing the results
 // of the IsDefs.
+//
+// A path may have several results, as when composed validators check the same path. They are kept in the
+// order they were recorded, and none replaces another: a failure always wins over a pass, making both the
+// path and the Results as a whole invalid, while the passes are kept alongside it.
 type Results struct {
 	Fields map[string][]ValueResult
 	Valid  bool
+	// Truncated is the number of failures that were not recorded because the limit set by Limit was reached.
+	Truncated int
+
+	maxFailures int
+	failures    int
+	// notes holds what's known about individual results beyond their ValueResult, by path and then index
+	// within Fields. It's kept apart so that the ValueResults compare equal to ValidVR and the like unless an
+	// option such as WithIsDefNames copies it into them.
+	notes map[string]map[int]resultNote
+	// declared holds the paths declared by the schemas that produced these Results, as written, so with any
+	// wildcards, and whether each was present in the validated value. It's what Coverage reports.
+	declared map[string]bool
+}
+
+// resultNote is what's known about a single ValueResult beyond its exported fields.
+type resultNote struct {
+	// isDefName is the Name of the IsDef that validated a passing value.
+	isDefName string
+	// coercion describes the type coercion a passing value needed, if any.
+	coercion string
 }
 
 // NewResults creates a new Results object.
@@ -39,7 +70,7 @@ func NewResults() *Results {
 // It's a very common way for validators to return a *Results object, and is generally simpler than
 // using SingleResult.
 func SimpleResult(path Path, valid bool, msg string, args ...interface{}) *Results {
-	vr := ValueResult{valid, fmt.Sprintf(msg, args...)}
+	vr := ValueResult{Valid: valid, Message: fmt.Sprintf(msg, args...)}
 	return SingleResult(path, vr)
 }
 
@@ -51,12 +82,99 @@ func SingleResult(path Path, result ValueResult) *Results {
 	return r
 }
 
+// Merge records all of the other Results into these ones. The other Results' paths are merged in sorted
+// order, and the results for each path in the order they were recorded, which are appended after any results
+// these Results already have for the same path. The merged Results are invalid if either was invalid.
+func (r *Results) Merge(other *Results) {
+	r.merge(other)
+}
+
 func (r *Results) merge(other *Results) {
-	for path, valueResults := range other.Fields {
-		for _, valueResult := range valueResults {
-			r.record(MustParsePath(path), valueResult)
+	r.mergeDeclared(other)
+	r.Truncated += other.Truncated
+	if !other.Valid {
+		// Needed when all of the other's failures were truncated
+		r.Valid = false
+	}
+	for _, path := range other.sortedPaths() {
+		for idx, valueResult := range other.Fields[path] {
+			r.recordFrom(path, valueResult, other, path, idx)
+		}
+	}
+}
+
+// sortedPaths returns the paths in Fields in sorted order, so that iterating over them is deterministic.
+func (r *Results) sortedPaths() []string {
+	paths := make([]string, 0, len(r.Fields))
+	for path := range r.Fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// nameValid notes the given IsDef name against the passing results that don't have one yet, so that a nested
+// IsDef which validated a value takes precedence over the IsDefs wrapping it. WithIsDefNames copies the
+// names into IsDefName. It returns the same Results.
+func (r *Results) nameValid(isDefName string) *Results {
+	if r == nil {
+		return r
+	}
+	for path, valueResults := range r.Fields {
+		for idx := range valueResults {
+			if valueResults[idx].Valid && r.notes[path][idx].isDefName == "" {
+				r.updateNote(path, idx, func(note *resultNote) {
+					note.isDefName = isDefName
+				})
+			}
 		}
 	}
+	return r
+}
+
+// withMessagePrefix returns a copy of the results in which the messages of failures are prefixed with the
+// given string, to add context such as the branch of a conditional that produced them.
+func (r *Results) withMessagePrefix(prefix string) *Results {
+	return r.withFailureMessages(func(msg string) string {
+		return prefix + msg
+	})
+}
+
+// failuresAsWarnings returns a copy of the results in which failures are turned into passing results with a
+// Warning holding their message, so that they're reported without affecting Valid.
+func (r *Results) failuresAsWarnings() *Results {
+	warned := NewResults()
+	warned.Truncated = r.Truncated
+	warned.mergeDeclared(r)
+	for path, valueResults := range r.Fields {
+		for idx, vr := range valueResults {
+			if !vr.Valid {
+				vr.Valid = true
+				vr.Warning = vr.Message
+			}
+			warned.recordFrom(path, vr, r, path, idx)
+		}
+	}
+	return warned
+}
+
+// withFailureMessages returns a copy of the results in which the messages of failures are replaced with the
+// result of passing them to the given function.
+func (r *Results) withFailureMessages(f func(msg string) string) *Results {
+	replaced := NewResults()
+	// Truncated failures aren't in Fields, so they're only accounted for here
+	replaced.Valid = r.Valid
+	replaced.Truncated = r.Truncated
+	replaced.mergeDeclared(r)
+	for path, valueResults := range r.Fields {
+		for idx, vr := range valueResults {
+			if !vr.Valid {
+				vr.Message = f(vr.Message)
+			}
+			replaced.recordFrom(path, vr, r, path, idx)
+		}
+	}
+	return replaced
 }
 
 // MergeUnderPrefix merges the given results at the path specified by the given prefix.
@@ -68,36 +186,102 @@ func (r *Results) MergeUnderPrefix(prefix Path, other *Results) {
 		return
 	}
 
-	for path, valueResults := range other.Fields {
-		for _, valueResult := range valueResults {
-			parsed := MustParsePath(path)
-			r.record(prefix.Concat(parsed), valueResult)
+	r.Truncated += other.Truncated
+	if !other.Valid {
+		r.Valid = false
+	}
+
+	prefixStr := prefix.String()
+	for _, path := range other.sortedPaths() {
+		// Path strings are joined with dots, so this is the same as prefix.Concat(MustParsePath(path)).String()
+		concatenated := prefixStr
+		if path != "" {
+			concatenated += "." + path
+		}
+		for idx, valueResult := range other.Fields[path] {
+			r.recordFrom(concatenated, valueResult, other, path, idx)
 		}
 	}
 }
 
+// record appends the result to those at the given path, marking the Results invalid if it is a failure.
+// Failures beyond the limit set by Limit are counted in Truncated instead of being recorded.
 func (r *Results) record(path Path, result ValueResult) {
-	if r.Fields[path.String()] == nil {
-		r.Fields[path.String()] = []ValueResult{result}
-	} else {
-		r.Fields[path.String()] = append(r.Fields[path.String()], result)
+	r.recordAt(path.String(), result)
+}
+
+// declare notes that the given path, as written in a schema, was checked, and whether it was present.
+func (r *Results) declare(path string, present bool) {
+	if r.declared == nil {
+		r.declared = map[string]bool{}
 	}
+	r.declared[path] = r.declared[path] || present
+}
 
+// mergeDeclared adds the paths declared by the schemas behind the other Results to these ones.
+func (r *Results) mergeDeclared(other *Results) {
+	for path, present := range other.declared {
+		r.declare(path, present)
+	}
+}
+
+// recordAt is record for a path that has already been converted to a string. It returns false if the result
+// was truncated rather than recorded.
+func (r *Results) recordAt(path string, result ValueResult) bool {
 	if !result.Valid {
 		r.Valid = false
+
+		if r.maxFailures > 0 && r.failures >= r.maxFailures {
+			r.Truncated++
+			return false
+		}
+		r.failures++
+	}
+
+	r.Fields[path] = append(r.Fields[path], result)
+	return true
+}
+
+// recordFrom records the given result, a copy of the one at index idx of otherPath in the other Results, at
+// the given path, along with its note.
+func (r *Results) recordFrom(path string, result ValueResult, other *Results, otherPath string, idx int) {
+	if !r.recordAt(path, result) {
+		return
+	}
+	if note, ok := other.notes[otherPath][idx]; ok {
+		r.updateNote(path, len(r.Fields[path])-1, func(n *resultNote) {
+			*n = note
+		})
+	}
+}
+
+// updateNote applies the given update to the note on the result at index idx of the given path.
+func (r *Results) updateNote(path string, idx int, update func(note *resultNote)) {
+	if r.notes == nil {
+		r.notes = map[string]map[int]resultNote{}
+	}
+	if r.notes[path] == nil {
+		r.notes[path] = map[int]resultNote{}
 	}
+	note := r.notes[path][idx]
+	update(&note)
+	r.notes[path][idx] = note
 }
 
 // EachResult executes the given callback once per Value result.
 // The provided callback can return true to keep iterating, or false
 // to stop.
+// Results are visited in a deterministic order: sorted by the string form of their paths, and in the order
+// they were recorded for results sharing a path. Since paths are compared as strings, index 10 of a slice
+// is visited before index 2.
 func (r Results) EachResult(f func(Path, ValueResult) bool) {
-	for path, pathResults := range r.Fields {
-		for _, result := range pathResults {
-			// We can ignore path parse errors here, those are from scalars and other
-			// types that have an invalid string path
-			// TODO: Find a cleaner way to do this
-			parsed, _ := ParsePath(path)
+	for _, path := range r.sortedPaths() {
+		parsed, err := ParsePath(path)
+		if err != nil {
+			// Fields is exported, so custom Validators may record keys that aren't valid paths
+			parsed = Path{{Type: pcMapKey, Key: path}}
+		}
+		for _, result := range r.Fields[path] {
 			if !f(parsed, result) {
 				return
 			}
@@ -130,6 +314,7 @@ func (vre ValueResultError) Error() string {
 }
 
 // Errors returns a list of error objects, one per failed value validation.
+// If failures were dropped due to a limit set with Limit, a final error noting how many is included.
 func (r Results) Errors() []error {
 	errors := make([]error, 0)
 
@@ -140,5 +325,255 @@ func (r Results) Errors() []error {
 		return true
 	})
 
+	if r.Truncated > 0 {
+		errors = append(errors, fmt.Errorf("%d additional failures were omitted due to a failure limit", r.Truncated))
+	}
+
 	return errors
 }
+
+// jsonResult is the serialized form of a single ValueResult used by Results.MarshalJSON.
+type jsonResult struct {
+	Path      string `json:"path"`
+	Valid     bool   `json:"valid"`
+	Message   string `json:"message"`
+	IsDefName string `json:"isdef,omitempty"`
+	Warning   string `json:"warning,omitempty"`
+}
+
+// Warnings returns a map of paths to the warnings recorded at those paths. Warnings don't affect Valid, and
+// paths without warnings are omitted.
+func (r Results) Warnings() map[string][]string {
+	warnings := map[string][]string{}
+	for path, pathResults := range r.Fields {
+		for _, vr := range pathResults {
+			if vr.Warning != "" {
+				warnings[path] = append(warnings[path], vr.Warning)
+			}
+		}
+	}
+	return warnings
+}
+
+// ErrorMessages returns a map of paths to the messages of failed validations at those paths.
+// Passing validations are omitted. If a path failed multiple validations their messages are joined with "; ".
+// This is a lighter weight alternative to Errors and DetailedErrors for reporting.
+func (r Results) ErrorMessages() map[string]string {
+	messages := map[string]string{}
+	for path, pathResults := range r.Fields {
+		for _, vr := range pathResults {
+			if vr.Valid {
+				continue
+			}
+
+			if existing, ok := messages[path]; ok {
+				messages[path] = existing + "; " + vr.Message
+			} else {
+				messages[path] = vr.Message
+			}
+		}
+	}
+	return messages
+}
+
+// formatValueResults renders a list of ValueResults compactly for use in Results.Diff.
+func formatValueResults(vrs []ValueResult) string {
+	out := make([]string, len(vrs))
+	for i, vr := range vrs {
+		status := "FAIL"
+		if vr.Valid {
+			status = "PASS"
+		}
+		out[i] = fmt.Sprintf("%s %q", status, vr.Message)
+	}
+	return "[" + strings.Join(out, ", ") + "]"
+}
+
+// Diff compares these Results against expected ones, and describes the differences in a deterministic,
+// human readable form, grouped into paths that were added (present only in these Results), removed
+// (present only in the expected Results), and changed (present in both, but with differing validity or
+// messages). An empty string is returned if there are no differences.
+func (r *Results) Diff(expected *Results) string {
+	var added, removed, changed []string
+	for path, vrs := range r.Fields {
+		expectedVrs, ok := expected.Fields[path]
+		if !ok {
+			added = append(added, fmt.Sprintf("  %s: %s", path, formatValueResults(vrs)))
+		} else if formatValueResults(vrs) != formatValueResults(expectedVrs) {
+			changed = append(changed, fmt.Sprintf(
+				"  %s: expected %s, got %s", path, formatValueResults(expectedVrs), formatValueResults(vrs),
+			))
+		}
+	}
+	for path, vrs := range expected.Fields {
+		if _, ok := r.Fields[path]; !ok {
+			removed = append(removed, fmt.Sprintf("  %s: %s", path, formatValueResults(vrs)))
+		}
+	}
+
+	sb := &strings.Builder{}
+	for _, group := range []struct {
+		name  string
+		lines []string
+	}{{"Added", added}, {"Removed", removed}, {"Changed", changed}} {
+		if len(group.lines) == 0 {
+			continue
+		}
+		sort.Strings(group.lines)
+		sb.WriteString(group.name + ":\n")
+		sb.WriteString(strings.Join(group.lines, "\n") + "\n")
+	}
+	return sb.String()
+}
+
+// MarshalJSON encodes the Results as an object with the overall validity under "valid", and a list of
+// {"path", "valid", "message"} objects under "results". The list is sorted by path, and results for the same
+// path keep the order they were recorded in, so the output is stable across runs.
+func (r Results) MarshalJSON() ([]byte, error) {
+	paths := r.sortedPaths()
+	entries := make([]jsonResult, 0, len(paths))
+	for _, path := range paths {
+		for _, vr := range r.Fields[path] {
+			entries = append(entries, jsonResult{path, vr.Valid, vr.Message, vr.IsDefName, vr.Warning})
+		}
+	}
+
+	return json.Marshal(struct {
+		Valid   bool         `json:"valid"`
+		Results []jsonResult `json:"results"`
+	}{r.Valid, entries})
+}
+
+// ToJSON returns the JSON encoded form of these Results, as described by MarshalJSON.
+func (r Results) ToJSON() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+// resultTreeNode is a node in the tree reconstructed from a Results' flattened paths by Results.Tree.
+type resultTreeNode struct {
+	component pathComponent
+	results   []ValueResult
+	children  map[string]*resultTreeNode
+}
+
+func newResultTreeNode(pc pathComponent) *resultTreeNode {
+	return &resultTreeNode{component: pc, children: map[string]*resultTreeNode{}}
+}
+
+// sortedChildren returns this node's children with slice indices in numeric order first, then map
+// keys in lexical order.
+func (n *resultTreeNode) sortedChildren() []*resultTreeNode {
+	sorted := make([]*resultTreeNode, 0, len(n.children))
+	for _, child := range n.children {
+		sorted = append(sorted, child)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i].component, sorted[j].component
+		if a.Type != b.Type {
+			return a.Type == pcSliceIdx
+		}
+		if a.Type == pcSliceIdx {
+			return a.Index < b.Index
+		}
+		return a.Key < b.Key
+	})
+	return sorted
+}
+
+const (
+	ansiGreen = "\x1b[32m"
+	ansiRed   = "\x1b[31m"
+	ansiReset = "\x1b[0m"
+)
+
+func (n *resultTreeNode) render(sb *strings.Builder, name string, depth int, color bool) {
+	pass, fail := "PASS", "FAIL"
+	if color {
+		pass, fail = ansiGreen+pass+ansiReset, ansiRed+fail+ansiReset
+	}
+
+	indent := strings.Repeat("  ", depth)
+	if len(n.results) == 0 {
+		sb.WriteString(indent + name + "\n")
+	}
+	for _, vr := range n.results {
+		if vr.Valid {
+			fmt.Fprintf(sb, "%s%s: %s\n", indent, name, pass)
+		} else {
+			fmt.Fprintf(sb, "%s%s: %s - %s\n", indent, name, fail, vr.Message)
+		}
+	}
+
+	for _, child := range n.sortedChildren() {
+		child.render(sb, child.component.String(), depth+1, color)
+	}
+}
+
+// Tree renders these Results as an indented tree mirroring the structure of the validated data, with one
+// PASS or FAIL line per result. This is much easier to read than the flattened paths in Fields when
+// debugging a large, deeply nested validation. Results recorded against the root itself, as happens when
+// validating a scalar, are listed under "(root)".
+func (r Results) Tree() string {
+	return r.tree(false)
+}
+
+// StringColor is like Tree, but colors passes green and failures red using ANSI escape codes.
+// If the NO_COLOR environment variable is set no colors are used, and the output is the same as Tree's.
+func (r Results) StringColor() string {
+	return r.tree(os.Getenv("NO_COLOR") == "")
+}
+
+// Fprint writes the tree form of these Results to w. Colors are used, as per StringColor, only if w is a
+// terminal, so it is safe to use with files and pipes.
+func (r Results) Fprint(w io.Writer) error {
+	var out string
+	if isTerminal(w) {
+		out = r.StringColor()
+	} else {
+		out = r.Tree()
+	}
+
+	_, err := io.WriteString(w, out)
+	return err
+}
+
+// isTerminal reports whether the given writer is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func (r Results) tree(color bool) string {
+	root := newResultTreeNode(pathComponent{})
+	r.EachResult(func(path Path, vr ValueResult) bool {
+		node := root
+		for _, pc := range path {
+			child, ok := node.children[pc.String()]
+			if !ok {
+				child = newResultTreeNode(pc)
+				node.children[pc.String()] = child
+			}
+			node = child
+		}
+		node.results = append(node.results, vr)
+		return true
+	})
+
+	sb := &strings.Builder{}
+	if len(root.results) > 0 {
+		rootOnly := &resultTreeNode{results: root.results}
+		rootOnly.render(sb, "(root)", 0, color)
+	}
+	for _, child := range root.sortedChildren() {
+		child.render(sb, child.component.String(), 0, color)
+	}
+	return sb.String()
+}
@@ -18,9 +18,12 @@
 package lookslike
 
 import (
+	"bytes"
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestEmpty(t *testing.T) {
@@ -46,3 +49,223 @@ func TestWithError(t *testing.T) {
 	assert.False(t, r.DetailedErrors().Valid)
 	assert.NotEmpty(t, r.Errors())
 }
+
+func TestMerge(t *testing.T) {
+	a := NewResults()
+	a.record(MustParsePath("foo"), ValidVR)
+	a.record(MustParsePath("bar"), ValidVR)
+
+	b := NewResults()
+	b.record(MustParsePath("foo"), KeyMissingVR)
+	b.record(MustParsePath("foo"), ValueResult{Valid: true, Message: "second"})
+	b.record(MustParsePath("baz"), ValidVR)
+
+	a.Merge(b)
+	assert.False(t, a.Valid)
+	// Results for the same path are appended in order, and the failure wins
+	assert.Equal(t, []ValueResult{ValidVR, KeyMissingVR, {Valid: true, Message: "second"}}, a.Fields["foo"])
+	assert.Equal(t, []ValueResult{ValidVR}, a.Fields["bar"])
+	assert.Equal(t, []ValueResult{ValidVR}, a.Fields["baz"])
+
+	// Invalidity survives merging even when every failure was truncated
+	onlyTruncated := NewResults()
+	onlyTruncated.Valid = false
+	onlyTruncated.Truncated = 1
+
+	merged := NewResults()
+	merged.Merge(onlyTruncated)
+	assert.False(t, merged.Valid)
+	assert.Equal(t, 1, merged.Truncated)
+}
+
+func TestWithMessagePrefix(t *testing.T) {
+	failing := MustCompile(Map{"a": IsString, "b": IsString, "c": IsString})
+	full := Limit(failing, 1)(Map{"a": 1, "b": 2, "c": "ok"})
+	require.Equal(t, 1, full.Truncated)
+
+	prefixed := full.withMessagePrefix("prefix: ")
+	assert.False(t, prefixed.Valid)
+	assert.Equal(t, 1, prefixed.Truncated)
+	assert.Equal(t, "prefix: "+full.Fields["a"][0].Message, prefixed.Fields["a"][0].Message)
+	assert.Equal(t, []ValueResult{ValidVR}, prefixed.Fields["c"])
+
+	// Results that are invalid only because of truncated failures stay invalid
+	onlyTruncated := NewResults()
+	onlyTruncated.Valid = false
+	onlyTruncated.Truncated = 2
+
+	prefixed = onlyTruncated.withMessagePrefix("prefix: ")
+	assert.False(t, prefixed.Valid)
+	assert.Equal(t, 2, prefixed.Truncated)
+}
+
+func TestFailuresAsWarnings(t *testing.T) {
+	full := Limit(MustCompile(Map{"a": IsString, "b": IsString}), 1)(Map{"a": 1, "b": 2})
+	require.Equal(t, 1, full.Truncated)
+
+	warned := full.failuresAsWarnings()
+	assert.True(t, warned.Valid)
+	assert.Equal(t, 1, warned.Truncated)
+	assert.Equal(t, map[string][]string{"a": {full.Fields["a"][0].Message}}, warned.Warnings())
+}
+
+func TestComposeOrder(t *testing.T) {
+	first := func(interface{}) *Results { return SimpleResult(MustParsePath("p"), true, "first") }
+	second := func(interface{}) *Results { return SimpleResult(MustParsePath("p"), false, "second") }
+
+	for i := 0; i < 10; i++ {
+		res := Compose(first, second)(nil)
+		assert.False(t, res.Valid)
+		assert.Equal(t, []ValueResult{{Valid: true, Message: "first"}, {Valid: false, Message: "second"}}, res.Fields["p"])
+	}
+}
+
+func TestEachResultOrder(t *testing.T) {
+	r := NewResults()
+	for _, p := range []string{"b.c", "a", "b", "a.[1]", "a.[0]", "c"} {
+		r.record(MustParsePath(p), ValueResult{Valid: true, Message: "first"})
+	}
+	r.record(MustParsePath("a"), ValueResult{Valid: false, Message: "second"})
+
+	for i := 0; i < 10; i++ {
+		var visited []string
+		r.EachResult(func(path Path, vr ValueResult) bool {
+			visited = append(visited, path.String()+" "+vr.Message)
+			return true
+		})
+		assert.Equal(t, []string{
+			"a first", "a second", "a.[0] first", "a.[1] first", "b first", "b.c first", "c first",
+		}, visited)
+	}
+}
+
+func TestEachResultUnparseablePath(t *testing.T) {
+	r := NewResults()
+	r.Valid = false
+	r.Fields["a."] = []ValueResult{KeyMissingVR}
+
+	// Keys that can't be parsed are visited as a single map key
+	var visited []Path
+	r.EachResult(func(path Path, vr ValueResult) bool {
+		visited = append(visited, path)
+		return true
+	})
+	assert.Equal(t, []Path{{{Type: pcMapKey, Key: "a."}}}, visited)
+
+	require.Len(t, r.Errors(), 1)
+	assert.Contains(t, r.Errors()[0].Error(), "a.")
+	errors := r.DetailedErrors()
+	assert.False(t, errors.Valid)
+	assert.Equal(t, []ValueResult{KeyMissingVR}, errors.Fields[visited[0].String()])
+	assert.Contains(t, r.Tree(), "a.")
+}
+
+func TestErrorMessages(t *testing.T) {
+	r := NewResults()
+	r.record(MustParsePath("foo"), KeyMissingVR)
+	r.record(MustParsePath("foo"), StrictFailureVR)
+	r.record(MustParsePath("bar"), ValidVR)
+
+	assert.Equal(
+		t,
+		map[string]string{"foo": KeyMissingVR.Message + "; " + StrictFailureVR.Message},
+		r.ErrorMessages(),
+	)
+	assert.Empty(t, NewResults().ErrorMessages())
+}
+
+func TestDiff(t *testing.T) {
+	expected := NewResults()
+	expected.record(MustParsePath("same"), ValidVR)
+	expected.record(MustParsePath("changed"), ValidVR)
+	expected.record(MustParsePath("removed"), ValidVR)
+
+	actual := NewResults()
+	actual.record(MustParsePath("same"), ValidVR)
+	actual.record(MustParsePath("changed"), KeyMissingVR)
+	actual.record(MustParsePath("added.b"), ValidVR)
+	actual.record(MustParsePath("added.a"), ValidVR)
+
+	want := `Added:
+  added.a: [PASS "is valid"]
+  added.b: [PASS "is valid"]
+Removed:
+  removed: [PASS "is valid"]
+Changed:
+  changed: expected [PASS "is valid"], got [FAIL "expected this key to be present"]
+`
+	assert.Equal(t, want, actual.Diff(expected))
+	assert.Equal(t, "", actual.Diff(actual))
+	assert.Equal(t, "", NewResults().Diff(NewResults()))
+}
+
+func TestToJSON(t *testing.T) {
+	r := NewResults()
+	r.record(MustParsePath("foo"), KeyMissingVR)
+	r.record(MustParsePath("bar.[0]"), ValidVR)
+	r.record(MustParsePath("bar.[0]"), StrictFailureVR)
+
+	encoded, err := r.ToJSON()
+	require.NoError(t, err)
+
+	expected := `{"valid":false,"results":[` +
+		`{"path":"bar.[0]","valid":true,"message":"is valid"},` +
+		`{"path":"bar.[0]","valid":false,"message":"unexpected field encountered during strict validation"},` +
+		`{"path":"foo","valid":false,"message":"expected this key to be present"}]}`
+	assert.JSONEq(t, expected, string(encoded))
+	assert.Equal(t, expected, string(encoded))
+
+	viaMarshal, err := json.Marshal(r)
+	require.NoError(t, err)
+	assert.Equal(t, encoded, viaMarshal)
+
+	empty, err := NewResults().ToJSON()
+	require.NoError(t, err)
+	assert.Equal(t, `{"valid":true,"results":[]}`, string(empty))
+}
+
+func TestTree(t *testing.T) {
+	r := NewResults()
+	r.record(MustParsePath("foo.bar"), KeyMissingVR)
+	r.record(MustParsePath("foo.baz"), ValidVR)
+	r.record(MustParsePath("arr.[10].a"), ValidVR)
+	r.record(MustParsePath("arr.[2].a"), ValidVR)
+	r.record(MustParsePath("top"), ValidVR)
+
+	expected := `arr
+  [2]
+    a: PASS
+  [10]
+    a: PASS
+foo
+  bar: FAIL - expected this key to be present
+  baz: PASS
+top: PASS
+`
+	assert.Equal(t, expected, r.Tree())
+	assert.Equal(t, "", NewResults().Tree())
+}
+
+func TestStringColor(t *testing.T) {
+	r := NewResults()
+	r.record(MustParsePath("foo"), KeyMissingVR)
+	r.record(MustParsePath("bar"), ValidVR)
+
+	t.Setenv("NO_COLOR", "")
+	colored := r.StringColor()
+	assert.Contains(t, colored, "bar: \x1b[32mPASS\x1b[0m\n")
+	assert.Contains(t, colored, "foo: \x1b[31mFAIL\x1b[0m - ")
+
+	t.Setenv("NO_COLOR", "1")
+	assert.Equal(t, r.Tree(), r.StringColor())
+}
+
+func TestFprint(t *testing.T) {
+	r := NewResults()
+	r.record(MustParsePath("foo"), KeyMissingVR)
+
+	// Non-terminals never get colors
+	buf := &bytes.Buffer{}
+	require.NoError(t, r.Fprint(buf))
+	assert.Equal(t, r.Tree(), buf.String())
+}
@@ -0,0 +1,64 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package assert provides a lookslike assertion in the style of github.com/stretchr/testify/assert, for
+// projects that standardize on testify.
+package assert
+
+import (
+	"fmt"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/lookslike/lookslike"
+)
+
+type tHelper interface {
+	Helper()
+}
+
+// LooksLike asserts that the actual value is valid according to the schema, which may be anything accepted by
+// lookslike.Compile, or an already compiled lookslike.Validator. On failure the failing paths are listed as a
+// tree. It returns whether the assertion succeeded, like other testify assertions.
+//
+//	assert.LooksLike(t, lookslike.Map{"id": lookslike.IsNumeric}, actual, "checking %s", name)
+func LooksLike(t assert.TestingT, schema interface{}, actual interface{}, msgAndArgs ...interface{}) bool {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+
+	validator, ok := schema.(lookslike.Validator)
+	if !ok {
+		var err error
+		validator, err = lookslike.Compile(schema)
+		if err != nil {
+			return assert.Fail(t, fmt.Sprintf("Could not compile lookslike schema: %v", err), msgAndArgs...)
+		}
+	}
+
+	results := validator(actual)
+	if results.Valid {
+		return true
+	}
+
+	failures := results.DetailedErrors()
+	return assert.Fail(
+		t,
+		fmt.Sprintf("Value does not look like the schema, %d errors:\n%s", len(failures.Errors()), failures.Tree()),
+		msgAndArgs...,
+	)
+}
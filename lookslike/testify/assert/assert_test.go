@@ -0,0 +1,57 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package assert
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/lookslike/lookslike"
+)
+
+// recordingT captures the failures reported to it instead of failing the real test.
+type recordingT struct {
+	errors []string
+}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestLooksLike(t *testing.T) {
+	schema := lookslike.Map{"foo": "bar", "n": lookslike.IsNumeric}
+
+	assert.True(t, LooksLike(t, schema, lookslike.Map{"foo": "bar", "n": 1}))
+	assert.True(t, LooksLike(t, lookslike.MustCompile(schema), lookslike.Map{"foo": "bar", "n": 1}))
+
+	rec := &recordingT{}
+	assert.False(t, LooksLike(rec, schema, lookslike.Map{"foo": "baz", "n": 1}, "checking %s", "thing"))
+	if assert.Len(t, rec.errors, 1) {
+		assert.Contains(t, rec.errors[0], "1 errors")
+		assert.Contains(t, rec.errors[0], "foo")
+		assert.Contains(t, rec.errors[0], "checking thing")
+	}
+
+	rec = &recordingT{}
+	assert.False(t, LooksLike(rec, 42, lookslike.Map{}))
+	if assert.Len(t, rec.errors, 1) {
+		assert.Contains(t, rec.errors[0], "Could not compile")
+	}
+}
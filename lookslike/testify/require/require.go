@@ -0,0 +1,41 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package require provides a lookslike assertion in the style of github.com/stretchr/testify/require, for
+// projects that standardize on testify.
+package require
+
+import (
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/lookslike/lookslike/testify/assert"
+)
+
+type tHelper interface {
+	Helper()
+}
+
+// LooksLike is like assert.LooksLike, but stops the test with t.FailNow if the assertion fails.
+func LooksLike(t require.TestingT, schema interface{}, actual interface{}, msgAndArgs ...interface{}) {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+
+	if !assert.LooksLike(t, schema, actual, msgAndArgs...) {
+		t.FailNow()
+	}
+}
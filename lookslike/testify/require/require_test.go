@@ -0,0 +1,54 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package require
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/lookslike/lookslike"
+)
+
+// recordingT captures the failures reported to it instead of failing the real test.
+type recordingT struct {
+	errors []string
+	failed bool
+}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingT) FailNow() {
+	r.failed = true
+}
+
+func TestLooksLike(t *testing.T) {
+	schema := lookslike.Map{"foo": "bar"}
+
+	rec := &recordingT{}
+	LooksLike(rec, schema, lookslike.Map{"foo": "bar"})
+	assert.False(t, rec.failed)
+	assert.Empty(t, rec.errors)
+
+	LooksLike(rec, schema, lookslike.Map{"foo": "baz"})
+	assert.True(t, rec.failed)
+	assert.Len(t, rec.errors, 1)
+}
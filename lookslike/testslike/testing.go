@@ -18,28 +18,28 @@
 package testslike
 
 import (
-	"github.com/elastic/lookslike/lookslike"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
-	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/lookslike/lookslike"
 )
 
-// Test takes the output from a Validator invocation and runs test assertions on the result.
+// Test runs the Validator against the value, and if it is invalid fails the test with t.Errorf, listing the
+// failures as a tree mirroring the structure of the value, followed by a dump of the value itself.
+// The Results are returned so callers can make further assertions on them.
 // If you are using this library for testing you will probably want to run Test(t, Compile(Map{...}), actual) as a pattern.
-func Test(t *testing.T, validator lookslike.Validator, value interface{}) *lookslike.Results {
+func Test(t testing.TB, validator lookslike.Validator, value interface{}) *lookslike.Results {
+	t.Helper()
 	r := validator(value)
 
 	if !r.Valid {
-		assert.Fail(
-			t,
-			"lookslike could not validate map",
-			"%d errors validating source: \n%s", len(r.Errors()), spew.Sdump(value),
+		errs := r.Errors()
+		t.Errorf(
+			"lookslike could not validate value, %d errors:\n%s\nvalue:\n%s",
+			len(errs), r.DetailedErrors().Tree(), spew.Sdump(value),
 		)
 	}
 
-	for _, err := range r.Errors() {
-		assert.NoError(t, err)
-	}
 	return r
 }
@@ -0,0 +1,56 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package testslike
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/lookslike/lookslike"
+)
+
+// recordingTB captures the failures reported to it instead of failing the real test.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestTest(t *testing.T) {
+	validator := lookslike.MustCompile(lookslike.Map{"foo": "bar", "nested": lookslike.Map{"n": lookslike.IsNumeric}})
+
+	res := Test(t, validator, lookslike.Map{"foo": "bar", "nested": lookslike.Map{"n": 1}})
+	assert.True(t, res.Valid)
+
+	rec := &recordingTB{}
+	res = Test(rec, validator, lookslike.Map{"foo": "baz", "nested": lookslike.Map{"n": 1}})
+	assert.False(t, res.Valid)
+	if assert.Len(t, rec.errors, 1) {
+		assert.Contains(t, rec.errors[0], "1 errors:")
+		assert.Contains(t, rec.errors[0], "foo")
+		assert.NotContains(t, rec.errors[0], "PASS")
+		assert.Contains(t, rec.errors[0], `"baz"`)
+	}
+}
@@ -17,10 +17,19 @@
 
 package lookslike
 
+import "fmt"
+
 // ValueResult represents the result of checking a leaf value.
 type ValueResult struct {
 	Valid   bool
 	Message string // Reason this is invalid
+	// IsDefName is the Name of the IsDef that validated a passing value when using WithIsDefNames, which
+	// tells which fields were asserted and by what. Failures describe themselves in Message instead, and
+	// leave it empty.
+	IsDefName string
+	// Warning describes a concern about a passing value that doesn't fail validation, such as it only
+	// passing after type coercion when using WithCoercionWarnings, or it failing an IsDef wrapped in AsWarning.
+	Warning string
 }
 
 // A ValueValidator is used to validate a value in a Map.
@@ -34,16 +43,48 @@ type IsDef struct {
 	Checker         ValueValidator
 	Optional        bool
 	CheckKeyMissing bool
+
+	// hasDefault is set by OptionalWithDefault, in which case missing keys are checked as if they had
+	// defaultValue.
+	hasDefault   bool
+	defaultValue interface{}
+
+	// failureMessage is set by WithMessage, in which case it replaces the messages of failures.
+	failureMessage string
+	// warning is set by AsWarning, in which case failures are recorded as warnings.
+	warning bool
+	// identity tells apart the IsDefs created by Is, whose Checkers can't be compared, for CompileCached.
+	identity uint64
 }
 
 // Check runs the IsDef at the given value at the given path
 func (id IsDef) Check(path Path, v interface{}, keyExists bool) *Results {
+	if id.warning {
+		inner := id
+		inner.warning = false
+		return inner.Check(path, v, keyExists).failuresAsWarnings()
+	}
+
+	if id.failureMessage != "" {
+		inner := id
+		inner.failureMessage = ""
+		return inner.Check(path, v, keyExists).withFailureMessages(func(string) string {
+			return id.failureMessage
+		})
+	}
+
+	if id.hasDefault && !keyExists {
+		dflt := id
+		dflt.hasDefault = false
+		return dflt.Check(path, id.defaultValue, true).withMessagePrefix(fmt.Sprintf("default value %#v: ", id.defaultValue))
+	}
+
 	if id.CheckKeyMissing {
 		if !keyExists {
-			return ValidResult(path)
+			return ValidResult(path).nameValid(id.Name)
 		}
 
-		return SimpleResult(path, false, "this key should not exist")
+		return SimpleResult(path, false, "this key should not exist, but it was present with value %#v", v)
 	}
 
 	if !id.Optional && !keyExists {
@@ -51,10 +92,21 @@ func (id IsDef) Check(path Path, v interface{}, keyExists bool) *Results {
 	}
 
 	if id.Checker != nil {
-		return id.Checker(path, v)
+		return id.runChecker(path, v).nameValid(id.Name)
 	}
 
-	return ValidResult(path)
+	return ValidResult(path).nameValid(id.Name)
+}
+
+// runChecker runs the Checker, turning a panic, such as one raised by a custom Checker given a value of a type
+// it doesn't expect, into a failure at the given path, so that validating untrusted input never panics.
+func (id IsDef) runChecker(path Path, v interface{}) (results *Results) {
+	defer func() {
+		if r := recover(); r != nil {
+			results = SimpleResult(path, false, "%s panicked while checking a %T: %v", id.Name, v, r)
+		}
+	}()
+	return id.Checker(path, v)
 }
 
 // ValidResult is a convenience value for Valid results.
@@ -62,8 +114,25 @@ func ValidResult(path Path) *Results {
 	return SimpleResult(path, true, "is valid")
 }
 
+// coercedResult is a ValidResult for a value that only passed after being coerced to the given type.
+func coercedResult(path Path, v interface{}, to string) *Results {
+	results := ValidResult(path)
+	results.updateNote(path.String(), 0, func(note *resultNote) {
+		note.coercion = fmt.Sprintf("passed only after coercing %T(%v) to %s", v, v, to)
+	})
+	return results
+}
+
+// numValidResult is a ValidResult for a number that was compared as a float64.
+func numValidResult(path Path, v interface{}) *Results {
+	if _, isFloat := v.(float64); isFloat {
+		return ValidResult(path)
+	}
+	return coercedResult(path, v, "float64")
+}
+
 // ValidVR is a convenience value for Valid results.
-var ValidVR = ValueResult{true, "is valid"}
+var ValidVR = ValueResult{Valid: true, Message: "is valid"}
 
 // KeyMissingResult is emitted when a key was expected, but was not present.
 func KeyMissingResult(path Path) *Results {
@@ -72,8 +141,8 @@ func KeyMissingResult(path Path) *Results {
 
 // KeyMissingVR is emitted when a key was expected, but was not present.
 var KeyMissingVR = ValueResult{
-	false,
-	"expected this key to be present",
+	Valid:   false,
+	Message: "expected this key to be present",
 }
 
 // StrictFailureResult is emitted when Strict() is used, and an unexpected field is found.
@@ -83,6 +152,6 @@ func StrictFailureResult(path Path) *Results {
 
 // StrictFailureVR is emitted when Strict() is used, and an unexpected field is found.
 var StrictFailureVR = ValueResult{
-	false,
-	"unexpected field encountered during strict validation",
+	Valid:   false,
+	Message: "unexpected field encountered during strict validation",
 }
@@ -18,42 +18,102 @@
 package lookslike
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
 )
 
+// errSkipChildren is returned by walkObservers to continue the walk without descending into the current value.
+var errSkipChildren = errors.New("skip children")
+
 type walkObserverInfo struct {
 	key     pathComponent
 	value   interface{}
 	rootMap Map
-	path    Path
+	// path shares its backing array with the paths of the values visited after it, so it's only valid
+	// for the duration of the observer call. Observers retaining it must copy it.
+	path Path
+	// cycle is set for a value within its own descendants, whose children aren't walked.
+	cycle bool
+}
+
+// cycleError is returned by walk when an observer, such as the one compiling a schema, can't proceed past a
+// value within its own descendants. Decoded JSON can't hold cycles, but documents built in code can.
+type cycleError struct {
+	path Path
+}
+
+func (e cycleError) Error() string {
+	return fmt.Sprintf("cycle detected at path %s", e.path)
 }
 
+// walkPathCapacity is the initial capacity of the Path reused while walking a tree, which avoids
+// reallocating it for most documents.
+const walkPathCapacity = 16
+
 // walkObserver functions run once per object in the tree.
 type walkObserver func(info walkObserverInfo) error
 
 // walk determine if in is a `Map` or a `Slice` and traverse it if so, otherwise will
 // treat it as a scalar and invoke the walk observer on the input value directly.
 func walk(in interface{}, expandPaths bool, wo walkObserver) error {
-	switch in.(type) {
+	ancestors := walkAncestors(in)
+	in = derefPointer(in)
+	switch typed := in.(type) {
 	case Map:
-		return walkMap(in.(Map), expandPaths, wo)
+		return walkFullMap(typed, typed, make(Path, 0, walkPathCapacity), ancestors, expandPaths, wo)
+	case map[string]interface{}:
+		return walkFullMap(Map(typed), Map(typed), make(Path, 0, walkPathCapacity), ancestors, expandPaths, wo)
 	case Slice:
-		return walkSlice(in.(Slice), expandPaths, wo)
+		return walkFullSlice(typed, Map{}, make(Path, 0, walkPathCapacity), ancestors, expandPaths, wo)
 	case []interface{}:
-		return walkSlice(Slice(in.([]interface{})), expandPaths, wo)
+		return walkFullSlice(Slice(typed), Map{}, make(Path, 0, walkPathCapacity), ancestors, expandPaths, wo)
 	default:
-		return walkScalar(in.(Scalar), expandPaths, wo)
+		if m, isStruct := convertStruct(in, rawValueToInterface); isStruct {
+			return walkFullMap(m, m, make(Path, 0, walkPathCapacity), ancestors, expandPaths, wo)
+		}
+		return walkScalar(Scalar(in), expandPaths, wo)
 	}
 }
 
 // walkMap is a shorthand way to walk a tree with a map as the root.
 func walkMap(m Map, expandPaths bool, wo walkObserver) error {
-	return walkFullMap(m, m, Path{}, expandPaths, wo)
+	return walkFullMap(m, m, make(Path, 0, walkPathCapacity), walkAncestors(m), expandPaths, wo)
 }
 
 // walkSlice walks the provided root slice.
 func walkSlice(s Slice, expandPaths bool, wo walkObserver) error {
-	return walkFullSlice(s, Map{}, Path{}, expandPaths, wo)
+	return walkFullSlice(s, Map{}, make(Path, 0, walkPathCapacity), walkAncestors(s), expandPaths, wo)
+}
+
+// walkAncestors returns the ancestors, as tracked by walkFull, of the children of the given root value.
+func walkAncestors(root interface{}) []valueID {
+	ancestors, _ := enterValue(make([]valueID, 0, walkPathCapacity), root)
+	return ancestors
+}
+
+// enterValue appends the identity of the given value, and of the value it points to if it's a pointer, to
+// ancestors. If either is already among ancestors, the value is within its own descendants, and cycle is true.
+func enterValue(ancestors []valueID, v interface{}) (updated []valueID, cycle bool) {
+	ids := make([]valueID, 0, 2)
+	if id, ok := valueIdentity(v); ok {
+		ids = append(ids, id)
+		if id.typ.Kind() == reflect.Ptr {
+			if pointee, ok := valueIdentity(derefPointer(v)); ok {
+				ids = append(ids, pointee)
+			}
+		}
+	}
+
+	for _, id := range ids {
+		for _, ancestor := range ancestors {
+			if ancestor == id {
+				return ancestors, true
+			}
+		}
+		ancestors = append(ancestors, id)
+	}
+	return ancestors, false
 }
 
 func walkScalar(s Scalar, expandPaths bool, wo walkObserver) error {
@@ -65,7 +125,12 @@ func walkScalar(s Scalar, expandPaths bool, wo walkObserver) error {
 	})
 }
 
-func walkFull(o interface{}, root Map, path Path, expandPaths bool, wo walkObserver) (err error) {
+// walkFull walks the given value and its descendants. The identities of the maps, slices and pointers
+// containing it are tracked in ancestors, which like path is appended to for children. A value found within
+// its own descendants is passed to the observer with cycle set, and its children aren't walked, since they
+// would be traversed forever. Pointers are followed, but only once their identities are tracked, which is why
+// values held by typed collections and structs are converted without dereferencing them.
+func walkFull(o interface{}, root Map, path Path, ancestors []valueID, expandPaths bool, wo walkObserver) (err error) {
 	lastPathComponent := path.Last()
 	if lastPathComponent == nil {
 		// In the case of a slice we can have an empty path
@@ -76,48 +141,73 @@ func walkFull(o interface{}, root Map, path Path, expandPaths bool, wo walkObser
 		}
 	}
 
-	err = wo(walkObserverInfo{*lastPathComponent, o, root, path})
+	ancestors, cycle := enterValue(ancestors, o)
+	o = derefPointer(o)
+
+	err = wo(walkObserverInfo{key: *lastPathComponent, value: o, rootMap: root, path: path, cycle: cycle})
+	if err == errSkipChildren {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
 
+	if o == nil || cycle {
+		// There's nothing to traverse beneath a nil value, and nothing new beneath a cycle
+		return nil
+	}
+
+	// Decoded JSON only contains these collection types, so handle them without reflection
+	switch typed := o.(type) {
+	case Map:
+		return walkFullMap(typed, root, path, ancestors, expandPaths, wo)
+	case map[string]interface{}:
+		return walkFullMap(Map(typed), root, path, ancestors, expandPaths, wo)
+	case Slice:
+		return walkFullSlice(typed, root, path, ancestors, expandPaths, wo)
+	case []interface{}:
+		return walkFullSlice(Slice(typed), root, path, ancestors, expandPaths, wo)
+	}
+
+	if m, isStruct := convertStruct(o, rawValueToInterface); isStruct {
+		return walkFullMap(m, root, path, ancestors, expandPaths, wo)
+	}
+
 	switch reflect.TypeOf(o).Kind() {
 	case reflect.Map:
-		converted := interfaceToMap(o)
-		err := walkFullMap(converted, root, path, expandPaths, wo)
+		converted := convertMap(o, rawValueToInterface)
+		err := walkFullMap(converted, root, path, ancestors, expandPaths, wo)
 		if err != nil {
 			return err
 		}
 	case reflect.Slice:
-		converted := sliceToSliceOfInterfaces(o)
-
-		for idx, v := range converted {
-			newPath := path.ExtendSlice(idx)
-			err := walkFull(v, root, newPath, expandPaths, wo)
-			if err != nil {
-				return err
-			}
+		converted := convertSlice(o, rawValueToInterface)
+		err := walkFullSlice(converted, root, path, ancestors, expandPaths, wo)
+		if err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
-// walkFullMap walks the given Map tree.
-func walkFullMap(m Map, root Map, p Path, expandPaths bool, wo walkObserver) (err error) {
+// walkFullMap walks the given Map tree. Rather than copying p for every child, as Path.Extend would, the
+// children's paths are appended to it, so that siblings, and the descendants of each, reuse the spare
+// capacity of the same backing array.
+func walkFullMap(m Map, root Map, p Path, ancestors []valueID, expandPaths bool, wo walkObserver) (err error) {
 	for k, v := range m {
 		var newPath Path
 		if !expandPaths {
-			newPath = p.ExtendMap(k)
+			newPath = append(p, pathComponent{pcMapKey, k, -1})
 		} else {
 			additionalPath, err := ParsePath(k)
 			if err != nil {
 				return err
 			}
-			newPath = p.Concat(additionalPath)
+			newPath = append(p, additionalPath...)
 		}
 
-		err = walkFull(v, root, newPath, expandPaths, wo)
+		err = walkFull(v, root, newPath, ancestors, expandPaths, wo)
 		if err != nil {
 			return err
 		}
@@ -126,12 +216,11 @@ func walkFullMap(m Map, root Map, p Path, expandPaths bool, wo walkObserver) (er
 	return nil
 }
 
-func walkFullSlice(s Slice, root Map, p Path, expandPaths bool, wo walkObserver) (err error) {
+func walkFullSlice(s Slice, root Map, p Path, ancestors []valueID, expandPaths bool, wo walkObserver) (err error) {
 	for idx, v := range s {
-		var newPath Path
-		newPath = p.ExtendSlice(idx)
+		newPath := append(p, pathComponent{pcSliceIdx, "", idx})
 
-		err = walkFull(v, root, newPath, expandPaths, wo)
+		err = walkFull(v, root, newPath, ancestors, expandPaths, wo)
 		if err != nil {
 			return err
 		}
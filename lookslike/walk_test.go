@@ -0,0 +1,192 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lookslike
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalkPaths(t *testing.T) {
+	m := Map{
+		"a": Map{
+			"b": 1,
+			"c": []interface{}{"x", Map{"d": 2}},
+		},
+		"e": nil,
+	}
+
+	var seen []string
+	err := walk(m, false, func(woi walkObserverInfo) error {
+		seen = append(seen, woi.path.String())
+		return nil
+	})
+	require.NoError(t, err)
+
+	sort.Strings(seen)
+	assert.Equal(t, []string{"a", "a.b", "a.c", "a.c.[0]", "a.c.[1]", "a.c.[1].d", "e"}, seen)
+}
+
+// walkCycles walks the given value, returning the paths at which cycles were detected.
+func walkCycles(t *testing.T, v interface{}) []string {
+	var cycles []string
+	err := walk(v, false, func(woi walkObserverInfo) error {
+		if woi.cycle {
+			cycles = append(cycles, woi.path.String())
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	sort.Strings(cycles)
+	return cycles
+}
+
+type testNode struct {
+	Name     string      `json:"name"`
+	Next     *testNode   `json:"next"`
+	Children []*testNode `json:"children"`
+}
+
+type testWrapper struct {
+	Inner testInner  `json:"inner"`
+	Ptr   *testInner `json:"ptr"`
+}
+
+type testInner struct {
+	Value int `json:"value"`
+}
+
+func TestWalkCycles(t *testing.T) {
+	self := map[string]interface{}{"a": 1}
+	self["self"] = self
+	assert.Equal(t, []string{"self"}, walkCycles(t, self))
+
+	// The cycle may pass through slices and other maps
+	outer := Map{}
+	inner := []interface{}{"x", Map{"back": outer}}
+	outer["list"] = inner
+	assert.Equal(t, []string{"root.list.[1].back"}, walkCycles(t, Map{"root": outer}))
+
+	loop := []interface{}{nil}
+	loop[0] = loop
+	assert.Equal(t, []string{"[0]"}, walkCycles(t, loop))
+
+	// Pointers to structs, the most common cycles in go, are tracked by their addresses
+	node := &testNode{Name: "a"}
+	node.Next = node
+	assert.Equal(t, []string{"next"}, walkCycles(t, node))
+
+	child := &testNode{Name: "b"}
+	parent := &testNode{Name: "a", Children: []*testNode{child}}
+	child.Children = []*testNode{parent}
+	assert.Equal(t, []string{"children.[0].children.[0]"}, walkCycles(t, parent))
+
+	// The same value referenced twice without containing itself is not a cycle
+	shared := Map{"x": []interface{}{1, 2}}
+	assert.Empty(t, walkCycles(t, Map{"a": shared, "b": shared, "c": Slice{shared, shared}}))
+	sharedNode := &testNode{Name: "c"}
+	assert.Empty(t, walkCycles(t, &testNode{Next: sharedNode, Children: []*testNode{sharedNode}}))
+
+	// Nor is a pointer to the first field of a struct, which shares the struct's address
+	wrapper := &testWrapper{}
+	wrapper.Ptr = &wrapper.Inner
+	assert.Empty(t, walkCycles(t, wrapper))
+}
+
+// nestedBenchmarkDocument returns a document shaped like a typical event, with nested maps and slices of
+// maps, along with a schema checking every leaf of it.
+func nestedBenchmarkDocument() (Map, Map) {
+	doc := Map{}
+	schema := Map{}
+	for i := 0; i < 10; i++ {
+		var hosts, hostSchemas []interface{}
+		for j := 0; j < 10; j++ {
+			hosts = append(hosts, Map{
+				"name": fmt.Sprintf("host-%d", j),
+				"ip":   fmt.Sprintf("10.0.%d.%d", i, j),
+				"os": Map{
+					"family":  "linux",
+					"version": Map{"major": 5, "minor": j},
+				},
+			})
+			hostSchemas = append(hostSchemas, Map{
+				"name": IsString,
+				"ip":   IsString,
+				"os": Map{
+					"family":  "linux",
+					"version": Map{"major": IsNumeric, "minor": j},
+				},
+			})
+		}
+		doc[fmt.Sprintf("service%d", i)] = Map{
+			"id":    i,
+			"tags":  []interface{}{"a", "b", "c"},
+			"hosts": hosts,
+		}
+		schema[fmt.Sprintf("service%d", i)] = Map{
+			"id":    IsNumeric,
+			"tags":  IsSliceOf(IsString),
+			"hosts": hostSchemas,
+		}
+	}
+	return doc, schema
+}
+
+func BenchmarkWalk(b *testing.B) {
+	doc, _ := nestedBenchmarkDocument()
+	b.ReportAllocs()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := walk(doc, false, func(woi walkObserverInfo) error {
+			return nil
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompile(b *testing.B) {
+	_, schema := nestedBenchmarkDocument()
+	b.ReportAllocs()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Compile(schema); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	doc, schema := nestedBenchmarkDocument()
+	validator := MustCompile(schema)
+	b.ReportAllocs()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !validator(doc).Valid {
+			b.Fatal("expected document to be valid")
+		}
+	}
+}